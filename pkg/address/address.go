@@ -2,19 +2,61 @@ package address
 
 import (
 	"Coin/pkg/pro"
+	"sync/atomic"
 	"time"
 )
 
+// Address is the address of a node.
+// Addr is the address ("host:port").
+// Transport is how requests reach the node (nil means
+// DefaultTransport).
+// sentVer is when we last sent the node a version, in unix
+// nanoseconds (see SentVer).
+// lastSeen is when the node was last heard of (see LastSeen).
 type Address struct {
-	Addr     string
-	LastSeen uint32
-	SentVer  time.Time
+	sentVer   int64
+	Addr      string
+	Transport *Transport
+	lastSeen  uint32
 }
 
 func New(addr string, lastSeen uint32) *Address {
-	return &Address{Addr: addr, LastSeen: lastSeen, SentVer: time.Time{}}
+	return &Address{Addr: addr, lastSeen: lastSeen}
 }
 
 func (a *Address) Serialize() *pro.Address {
-	return &pro.Address{Addr: a.Addr, LastSeen: a.LastSeen}
+	return &pro.Address{Addr: a.Addr, LastSeen: a.LastSeen()}
+}
+
+// LastSeen returns when the node was last heard of, in unix
+// seconds.
+func (a *Address) LastSeen() uint32 {
+	return atomic.LoadUint32(&a.lastSeen)
+}
+
+// SetLastSeen records when the node was last heard of.
+func (a *Address) SetLastSeen(lastSeen uint32) {
+	atomic.StoreUint32(&a.lastSeen, lastSeen)
+}
+
+// SentVer returns when we last sent the node a version (the
+// zero time if we never did).
+func (a *Address) SentVer() time.Time {
+	if ns := atomic.LoadInt64(&a.sentVer); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// SetSentVer records that we sent the node a version at t.
+func (a *Address) SetSentVer(t time.Time) {
+	atomic.StoreInt64(&a.sentVer, t.UnixNano())
+}
+
+// transport returns the transport requests to the address use.
+func (a *Address) transport() *Transport {
+	if a.Transport == nil {
+		return DefaultTransport
+	}
+	return a.Transport
 }
@@ -5,14 +5,28 @@ import (
 	"Coin/pkg/pro"
 )
 
+// AddressDb keeps the addresses we know of (see EphemeralAddressDb).
+// Add takes the address of the node that told us about the address.
+// Good and Attempt record that connecting to an address worked or failed.
+// Select picks addresses to connect to.
 type AddressDb interface {
-	Add(*address.Address) error
+	Add(*address.Address, string) error
 	Get(string) *address.Address
 	UpdateLastSeen(string, uint32) error
 	List() []*address.Address
 	Serialize() []*pro.Address
+	Good(string)
+	Attempt(string)
+	Select(int) []*address.Address
+	Close()
 }
 
-func New(eph bool, limit int) AddressDb {
-	return &EphemeralAddressDb{addresses: make(map[string]*address.Address), limit: limit}
+// New returns an AddressDb. If eph is false, the addresses
+// are also stored in a levelDB at path, so that they
+// survive restarts.
+func New(eph bool, limit int, path string) AddressDb {
+	if !eph {
+		return NewPersistentDb(limit, path)
+	}
+	return newEphemeralDb(limit)
 }
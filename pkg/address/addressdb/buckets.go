@@ -0,0 +1,81 @@
+package addressdb
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"net"
+	"strconv"
+)
+
+// The address table is split into "new" buckets, for addresses
+// we have only heard about, and "tried" buckets, for addresses
+// we have successfully connected to. Which bucket an address
+// goes into is decided by a keyed hash, so that a peer can't
+// pick the buckets its addresses land in.
+// NewBucketCount is the number of new buckets.
+// TriedBucketCount is the number of tried buckets.
+// BucketSize is how many addresses a bucket holds.
+// newBucketsPerSource is how many new buckets the addresses
+// from one source group can be in, which limits how much of
+// the table a single peer (or network) can fill.
+// triedBucketsPerGroup is how many tried buckets the addresses
+// of one group can be in.
+const (
+	NewBucketCount       = 64
+	TriedBucketCount     = 16
+	BucketSize           = 16
+	newBucketsPerSource  = 8
+	triedBucketsPerGroup = 4
+)
+
+// Group returns the network group of an address ("host:port"):
+// the /16 prefix of an IPv4 address, the /32 prefix of an IPv6
+// address, or the host name. Addresses in the same group are
+// likely to be run by the same operator.
+func Group(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(16, 32)).String() + "/16"
+	}
+	return ip.Mask(net.CIDRMask(32, 128)).String() + "/32"
+}
+
+// bucketHash returns a hash of the parts keyed with key.
+func bucketHash(key []byte, parts ...string) uint64 {
+	h := sha256.New()
+	h.Write(key)
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil)[:8])
+}
+
+// newBucket returns the new bucket of an address that we
+// heard about from source. All the addresses from one source
+// group land in at most newBucketsPerSource buckets.
+func newBucket(key []byte, addr string, source string) int {
+	g, sg := Group(addr), Group(source)
+	i := bucketHash(key, "group", g, sg) % newBucketsPerSource
+	return int(bucketHash(key, "new", sg, strconv.FormatUint(i, 10)) % NewBucketCount)
+}
+
+// triedBucket returns the tried bucket of an address. All the
+// addresses of one group land in at most triedBucketsPerGroup
+// buckets.
+func triedBucket(key []byte, addr string) int {
+	i := bucketHash(key, "addr", addr) % triedBucketsPerGroup
+	return int(bucketHash(key, "tried", Group(addr), strconv.FormatUint(i, 10)) % TriedBucketCount)
+}
+
+// slot returns the position an address prefers in a bucket.
+func slot(key []byte, bucket int, addr string) int {
+	return int(bucketHash(key, "slot", strconv.Itoa(bucket), addr) % BucketSize)
+}
@@ -3,54 +3,288 @@ package addressdb
 import (
 	"Coin/pkg/address"
 	"Coin/pkg/pro"
+	"crypto/rand"
 	"errors"
+	"math"
+	mrand "math/rand"
 	"sync"
 )
 
+// maxAttempts is how many connection attempts in a row may fail
+// before a new address is terrible, and is evicted first.
+const maxAttempts = 3
+
+// entry is an address in the address table.
+// addr is the address.
+// source is the address of the node we heard about it from.
+// tried is whether we have connected to the address.
+// bucket and slot are where the address is in its table.
+// attempts is how many connection attempts in a row failed.
+type entry struct {
+	addr     *address.Address
+	source   string
+	tried    bool
+	bucket   int
+	slot     int
+	attempts int
+}
+
+// terrible returns whether a new address has failed so often
+// that it should be the first to go.
+func (e *entry) terrible() bool {
+	return !e.tried && e.attempts >= maxAttempts
+}
+
+// EphemeralAddressDb is an AddressDb kept in memory. Addresses
+// are stored in new and tried buckets (see NewBucketCount).
+// addresses maps addresses to their entries.
+// newTable and triedTable are the buckets.
+// key is the secret key that decides the buckets.
+// limit is the most addresses the table holds.
+// changed and removed are called (with the lock held) when an
+// address is added or changed, and when one is evicted.
 type EphemeralAddressDb struct {
-	addresses map[string]*address.Address
-	limit     int
+	addresses  map[string]*entry
+	newTable   [NewBucketCount][BucketSize]*entry
+	triedTable [TriedBucketCount][BucketSize]*entry
+	key        []byte
+	limit      int
+	changed    func(*entry)
+	removed    func(string)
 	sync.Mutex
 }
 
-// Add Returns true if address was added (or modified)
-func (adb *EphemeralAddressDb) Add(a *address.Address) error {
-	oldA := adb.addresses[a.Addr]
-	if oldA != nil {
+func newEphemeralDb(limit int) *EphemeralAddressDb {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return NewKeyedDb(limit, key)
+}
+
+// NewKeyedDb returns an EphemeralAddressDb whose buckets are
+// decided by the given key rather than a random one, so that
+// where its addresses land can be reproduced (for testing).
+func NewKeyedDb(limit int, key []byte) *EphemeralAddressDb {
+	return &EphemeralAddressDb{
+		addresses: make(map[string]*entry),
+		key:       key,
+		limit:     limit,
+		changed:   func(*entry) {},
+		removed:   func(string) {},
+	}
+}
+
+// Add adds an address we heard about from source (the address
+// of the node that told us, or the address itself if that node
+// told us about itself) to a new bucket. If the bucket is full,
+// its worst address is evicted, as long as that address is
+// terrible or older than the new one.
+func (adb *EphemeralAddressDb) Add(a *address.Address, source string) error {
+	adb.Lock()
+	defer adb.Unlock()
+	if adb.addresses[a.Addr] != nil {
 		return errors.New("address already exists")
 	}
 	if len(adb.addresses) >= adb.limit {
 		return errors.New("address list full")
 	}
-	adb.addresses[a.Addr] = a
+	e := &entry{addr: a, source: source}
+	if !adb.placeNew(e) {
+		return errors.New("bucket is full")
+	}
 	return nil
 }
 
 func (adb *EphemeralAddressDb) Get(addr string) *address.Address {
-	return adb.addresses[addr]
+	adb.Lock()
+	defer adb.Unlock()
+	if e := adb.addresses[addr]; e != nil {
+		return e.addr
+	}
+	return nil
 }
 
 func (adb *EphemeralAddressDb) UpdateLastSeen(addr string, lastSeen uint32) error {
-	a := adb.addresses[addr]
-	if a == nil {
+	adb.Lock()
+	defer adb.Unlock()
+	e := adb.addresses[addr]
+	if e == nil {
 		return errors.New("address not found")
 	}
-	a.LastSeen = lastSeen
+	e.addr.SetLastSeen(lastSeen)
+	adb.changed(e)
 	return nil
 }
 
 func (adb *EphemeralAddressDb) List() []*address.Address {
+	adb.Lock()
+	defer adb.Unlock()
 	addresses := make([]*address.Address, 0, len(adb.addresses))
-	for _, addr := range adb.addresses {
-		addresses = append(addresses, addr)
+	for _, e := range adb.addresses {
+		addresses = append(addresses, e.addr)
 	}
 	return addresses
 }
 
 func (adb *EphemeralAddressDb) Serialize() []*pro.Address {
+	adb.Lock()
+	defer adb.Unlock()
 	addresses := make([]*pro.Address, 0, len(adb.addresses))
-	for _, addr := range adb.addresses {
-		addresses = append(addresses, addr.Serialize())
+	for _, e := range adb.addresses {
+		addresses = append(addresses, e.addr.Serialize())
 	}
 	return addresses
 }
+
+// Good records that we connected to an address, which moves it
+// to a tried bucket. If the bucket is full, its oldest address
+// goes back to the new buckets.
+func (adb *EphemeralAddressDb) Good(addr string) {
+	adb.Lock()
+	defer adb.Unlock()
+	e := adb.addresses[addr]
+	if e == nil {
+		return
+	}
+	e.attempts = 0
+	if e.tried {
+		adb.changed(e)
+		return
+	}
+	adb.newTable[e.bucket][e.slot] = nil
+	b := triedBucket(adb.key, addr)
+	s, ok := adb.freeSlot(&adb.triedTable[b], slot(adb.key, b, addr))
+	if !ok {
+		victim := adb.worst(&adb.triedTable[b])
+		s = victim.slot
+		adb.triedTable[b][s] = nil
+		victim.tried = false
+		if !adb.placeNew(victim) {
+			delete(adb.addresses, victim.addr.Addr)
+			adb.removed(victim.addr.Addr)
+		}
+	}
+	e.tried, e.bucket, e.slot = true, b, s
+	adb.triedTable[b][s] = e
+	adb.changed(e)
+}
+
+// Attempt records that connecting to an address failed.
+func (adb *EphemeralAddressDb) Attempt(addr string) {
+	adb.Lock()
+	defer adb.Unlock()
+	if e := adb.addresses[addr]; e != nil {
+		e.attempts++
+		adb.changed(e)
+	}
+}
+
+// Select returns up to n addresses to connect to. Tried and new
+// addresses are equally likely to be picked, and every pick is
+// from a random bucket, so that addresses that fill a few
+// buckets (such as those of a single peer's sybils) are rarely
+// picked. Addresses that failed to connect are less likely
+// to be picked.
+func (adb *EphemeralAddressDb) Select(n int) []*address.Address {
+	adb.Lock()
+	defer adb.Unlock()
+	tried := 0
+	for _, e := range adb.addresses {
+		if e.tried {
+			tried++
+		}
+	}
+	var selected []*address.Address
+	picked := make(map[string]bool)
+	for i := 0; i < 20*n && len(selected) < n && len(picked) < len(adb.addresses); i++ {
+		var e *entry
+		if tried > 0 && (tried == len(adb.addresses) || mrand.Intn(2) == 0) {
+			e = randomEntry(adb.triedTable[:])
+		} else {
+			e = randomEntry(adb.newTable[:])
+		}
+		if e == nil || picked[e.addr.Addr] {
+			continue
+		}
+		if mrand.Float64() >= math.Pow(0.66, float64(e.attempts)) {
+			continue
+		}
+		picked[e.addr.Addr] = true
+		selected = append(selected, e.addr)
+	}
+	return selected
+}
+
+func (adb *EphemeralAddressDb) Close() {}
+
+// placeNew puts an entry in its new bucket, evicting the worst
+// address in the bucket if it is full and the entry is better.
+// Returns:
+// bool true if the entry was placed
+func (adb *EphemeralAddressDb) placeNew(e *entry) bool {
+	b := newBucket(adb.key, e.addr.Addr, e.source)
+	s, ok := adb.freeSlot(&adb.newTable[b], slot(adb.key, b, e.addr.Addr))
+	if !ok {
+		victim := adb.worst(&adb.newTable[b])
+		if !victim.terrible() && victim.addr.LastSeen() >= e.addr.LastSeen() {
+			return false
+		}
+		s = victim.slot
+		delete(adb.addresses, victim.addr.Addr)
+		adb.removed(victim.addr.Addr)
+	}
+	e.tried, e.bucket, e.slot = false, b, s
+	adb.newTable[b][s] = e
+	adb.addresses[e.addr.Addr] = e
+	adb.changed(e)
+	return true
+}
+
+// freeSlot returns the first free slot in a bucket at or
+// after the preferred one.
+func (adb *EphemeralAddressDb) freeSlot(bucket *[BucketSize]*entry, preferred int) (int, bool) {
+	for i := 0; i < BucketSize; i++ {
+		s := (preferred + i) % BucketSize
+		if bucket[s] == nil {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// worst returns the address in a full bucket that should be
+// evicted first: a terrible one, or else the oldest one.
+func (adb *EphemeralAddressDb) worst(bucket *[BucketSize]*entry) *entry {
+	w := bucket[0]
+	for _, e := range bucket[1:] {
+		if e.terrible() && !w.terrible() || e.terrible() == w.terrible() && e.addr.LastSeen() < w.addr.LastSeen() {
+			w = e
+		}
+	}
+	return w
+}
+
+// randomEntry returns a random address from a random non-empty
+// bucket of a table, or nil if the table is empty.
+func randomEntry(table [][BucketSize]*entry) *entry {
+	var buckets []int
+	for b := range table {
+		for _, e := range table[b] {
+			if e != nil {
+				buckets = append(buckets, b)
+				break
+			}
+		}
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+	var entries []*entry
+	for _, e := range table[buckets[mrand.Intn(len(buckets))]] {
+		if e != nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries[mrand.Intn(len(entries))]
+}
@@ -0,0 +1,82 @@
+package addressdb
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"github.com/syndtr/goleveldb/leveldb"
+	"google.golang.org/protobuf/proto"
+)
+
+// PersistentAddressDb is an AddressDb that keeps its addresses
+// in memory (so that every Get of an address returns the same
+// Address), and writes them through to a levelDB. Only the
+// addresses are stored, not their buckets, since the buckets
+// depend on a key that is new every run.
+type PersistentAddressDb struct {
+	*EphemeralAddressDb
+	db *leveldb.DB
+}
+
+// NewPersistentDb returns a PersistentAddressDb with the
+// addresses stored in the levelDB at path.
+func NewPersistentDb(limit int, path string) *PersistentAddressDb {
+	adb := &PersistentAddressDb{EphemeralAddressDb: newEphemeralDb(limit)}
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		utils.Debug.Printf("Unable to initialize AddressDb with path {%v}", path)
+		return adb
+	}
+	adb.db = db
+	adb.removed = adb.delete
+	iter := db.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		r := &pro.AddressRecord{}
+		if err := proto.Unmarshal(iter.Value(), r); err != nil || r.Address == nil {
+			utils.Debug.Printf("Failed to unmarshal address {%v}: %v", string(iter.Key()), err)
+			continue
+		}
+		a := address.New(r.Address.Addr, r.Address.LastSeen)
+		if err := adb.EphemeralAddressDb.Add(a, r.Source); err != nil {
+			continue
+		}
+		if r.Tried {
+			adb.EphemeralAddressDb.Good(a.Addr)
+		}
+		adb.addresses[a.Addr].attempts = int(r.Attempts)
+	}
+	adb.changed = adb.store
+	return adb
+}
+
+// Close is used to actually shut down the db (for testing purposes)
+func (adb *PersistentAddressDb) Close() {
+	if adb.db != nil {
+		adb.db.Close()
+	}
+}
+
+// store writes an address to the levelDB.
+func (adb *PersistentAddressDb) store(e *entry) {
+	bytes, err := proto.Marshal(&pro.AddressRecord{
+		Address:  e.addr.Serialize(),
+		Source:   e.source,
+		Tried:    e.tried,
+		Attempts: uint32(e.attempts),
+	})
+	if err != nil {
+		utils.Debug.Printf("Failed to marshal address: %v", err)
+		return
+	}
+	if err = adb.db.Put([]byte(e.addr.Addr), bytes, nil); err != nil {
+		utils.Debug.Printf("Unable to store address {%v}", e.addr.Addr)
+	}
+}
+
+// delete removes an evicted address from the levelDB.
+func (adb *PersistentAddressDb) delete(addr string) {
+	if err := adb.db.Delete([]byte(addr), nil); err != nil {
+		utils.Debug.Printf("Unable to delete address {%v}", addr)
+	}
+}
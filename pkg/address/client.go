@@ -2,7 +2,6 @@ package address
 
 import (
 	"Coin/pkg/pro"
-	"fmt"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"time"
@@ -11,6 +10,10 @@ import (
 // RPCTimeout is default timeout for rpc client calls
 const RPCTimeout = 2 * time.Second
 
+// MaxMessageSize is the largest message nodes send or receive
+// by default (it has to fit a block of the largest size)
+const MaxMessageSize = 16 << 20
+
 // clientUnaryInterceptor is a client unary interceptor that injects a default timeout
 func clientUnaryInterceptor(
 	ctx context.Context,
@@ -25,18 +28,12 @@ func clientUnaryInterceptor(
 	return invoker(ctx, method, req, reply, cc, opts...)
 }
 
-func connectToServer(addr string) (*grpc.ClientConn, error) {
-	return grpc.Dial(addr, []grpc.DialOption{
-		grpc.WithInsecure(),
-		grpc.FailOnNonTempDialError(true),
-		grpc.WithUnaryInterceptor(clientUnaryInterceptor),
-	}...)
-}
-
-// TODO: cache client connections (optional feature)
+// GetConnection dials a new connection to the node, for
+// callers that need a client of their own. The RPC methods
+// below share one session per node instead (see session).
 // Returns callback to close connection
 func (a *Address) GetConnection() (pro.CoinClient, *grpc.ClientConn, error) {
-	cc, err := connectToServer(a.Addr)
+	cc, err := a.transport().connect(a.Addr)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -44,114 +41,119 @@ func (a *Address) GetConnection() (pro.CoinClient, *grpc.ClientConn, error) {
 }
 
 func (a *Address) VersionRPC(request *pro.VersionRequest) (*pro.Empty, error) {
-	c, cc, err := a.GetConnection()
+	reply := &pro.Empty{}
+	err := a.transport().session(a.Addr).call("Version", request, reply)
+	a.SetSentVer(time.Now())
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := cc.Close()
-		if err != nil {
-			fmt.Printf("ERROR {Address.VersionRPC}: " +
-				"error when closing connection")
-		}
-	}()
-	reply, err := c.Version(context.Background(), request)
-	a.SentVer = time.Now()
-	return reply, err
+	return reply, nil
 }
 
 func (a *Address) GetBlocksRPC(request *pro.GetBlocksRequest) (*pro.GetBlocksResponse, error) {
-	c, cc, err := a.GetConnection()
+	reply := &pro.GetBlocksResponse{}
+	err := a.transport().session(a.Addr).call("GetBlocks", request, reply)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := cc.Close()
-		if err != nil {
-			fmt.Printf("ERROR {Address.GetBlocksRPC}: " +
-				"error when closing connection")
-		}
-	}()
-	reply, err := c.GetBlocks(context.Background(), request)
-	return reply, err
+	return reply, nil
 }
 
 func (a *Address) GetDataRPC(request *pro.GetDataRequest) (*pro.GetDataResponse, error) {
-	c, cc, err := a.GetConnection()
+	reply := &pro.GetDataResponse{}
+	err := a.transport().session(a.Addr).call("GetData", request, reply)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := cc.Close()
-		if err != nil {
-			fmt.Printf("ERROR {Address.GetDataRPC}: " +
-				"error when closing connection")
-		}
-	}()
-	reply, err := c.GetData(context.Background(), request)
-	return reply, err
+	return reply, nil
 }
 
 func (a *Address) GetAddressesRPC(request *pro.Empty) (*pro.Addresses, error) {
-	c, cc, err := a.GetConnection()
+	reply := &pro.Addresses{}
+	err := a.transport().session(a.Addr).call("GetAddresses", request, reply)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := cc.Close()
-		if err != nil {
-			fmt.Printf("ERROR {Address.GetAddressesRPC}: " +
-				"error when closing connection")
-		}
-	}()
-	reply, err := c.GetAddresses(context.Background(), request)
-	return reply, err
+	return reply, nil
 }
 
 func (a *Address) SendAddressesRPC(request *pro.Addresses) (*pro.Empty, error) {
-	c, cc, err := a.GetConnection()
+	reply := &pro.Empty{}
+	err := a.transport().session(a.Addr).call("SendAddresses", request, reply)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := cc.Close()
-		if err != nil {
-			fmt.Printf("ERROR {Address.SendAddressesRPC}: " +
-				"error when closing connection")
-		}
-	}()
-	reply, err := c.SendAddresses(context.Background(), request)
-	return reply, err
+	return reply, nil
 }
 
 func (a *Address) ForwardTransactionRPC(request *pro.Transaction) (*pro.Empty, error) {
-	c, cc, err := a.GetConnection()
+	reply := &pro.Empty{}
+	err := a.transport().session(a.Addr).call("ForwardTransaction", request, reply)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := cc.Close()
-		if err != nil {
-			fmt.Printf("ERROR {Address.ForwardTransactionRPC}: " +
-				"error when closing connection")
-		}
-	}()
-	reply, err := c.ForwardTransaction(context.Background(), request)
-	return reply, err
+	return reply, nil
 }
 
 func (a *Address) ForwardBlockRPC(request *pro.Block) (*pro.Empty, error) {
-	c, cc, err := a.GetConnection()
+	reply := &pro.Empty{}
+	err := a.transport().session(a.Addr).call("ForwardBlock", request, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (a *Address) GetMerkleProofRPC(request *pro.GetMerkleProofRequest) (*pro.GetMerkleProofResponse, error) {
+	reply := &pro.GetMerkleProofResponse{}
+	err := a.transport().session(a.Addr).call("GetMerkleProof", request, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (a *Address) GetHeadersRPC(request *pro.GetHeadersRequest) (*pro.GetHeadersResponse, error) {
+	reply := &pro.GetHeadersResponse{}
+	err := a.transport().session(a.Addr).call("GetHeaders", request, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (a *Address) AnnounceRPC(request *pro.Inventory) (*pro.Empty, error) {
+	reply := &pro.Empty{}
+	err := a.transport().session(a.Addr).call("Announce", request, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (a *Address) GetObjectsRPC(request *pro.GetObjectsRequest) (*pro.GetObjectsResponse, error) {
+	reply := &pro.GetObjectsResponse{}
+	err := a.transport().session(a.Addr).call("GetObjects", request, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (a *Address) GetBlockTransactionsRPC(request *pro.GetBlockTransactionsRequest) (*pro.GetBlockTransactionsResponse, error) {
+	reply := &pro.GetBlockTransactionsResponse{}
+	err := a.transport().session(a.Addr).call("GetBlockTransactions", request, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (a *Address) PingRPC(request *pro.PingRequest) (*pro.PingResponse, error) {
+	reply := &pro.PingResponse{}
+	err := a.transport().session(a.Addr).call("Ping", request, reply)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := cc.Close()
-		if err != nil {
-			fmt.Printf("ERROR {Address.ForwardBlockRPC}: " +
-				"error when closing connection")
-		}
-	}()
-	reply, err := c.ForwardBlock(context.Background(), request)
-	return reply, err
+	return reply, nil
 }
@@ -0,0 +1,227 @@
+package address
+
+import (
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"sync"
+	"time"
+)
+
+// MaxInFlight is the most requests a session has waiting for
+// a response at once. Further requests wait (up to RPCTimeout)
+// for one of them to finish, and a node handles at most this
+// many requests of a session at once.
+const MaxInFlight = 64
+
+// After a session fails to reconnect, calls fail right away
+// for a backoff, which starts at minBackoff and doubles (up to
+// maxBackoff) every time reconnecting fails again.
+const (
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 10 * time.Second
+)
+
+var (
+	errBackoff       = errors.New("waiting to reconnect")
+	errSessionClosed = errors.New("session closed")
+	errTimeout       = errors.New("request timed out")
+)
+
+// session is a long-lived stream to a node, which carries all
+// of our requests to it (see call).
+// transport is the transport the session belongs to.
+// addr is the address of the node.
+// cc and stream are the connection and stream (nil while
+// the session is not connected).
+// cancel ends the stream.
+// pending maps the ids of requests to the channels their
+// responses are delivered on.
+// nextID is the id of the last request.
+// backoff and retryAt are the current backoff, and when
+// the session may try to reconnect.
+// shut is whether the session was shut down for good (see
+// shutdown).
+// mutex guards the fields above, and sendMutex guards sending.
+// slots holds a token for every request in flight.
+type session struct {
+	transport *Transport
+	addr      string
+	cc        *grpc.ClientConn
+	stream    pro.Coin_SessionClient
+	cancel    context.CancelFunc
+	pending   map[uint64]chan *pro.Envelope
+	nextID    uint64
+	backoff   time.Duration
+	retryAt   time.Time
+	shut      bool
+	mutex     sync.Mutex
+	sendMutex sync.Mutex
+	slots     chan struct{}
+}
+
+// session returns the transport's session to the node at addr.
+// A closed transport only has sessions that are shut down.
+func (t *Transport) session(addr string) *session {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.sessions[addr]
+	if s == nil {
+		s = &session{
+			transport: t,
+			addr:      addr,
+			pending:   make(map[uint64]chan *pro.Envelope),
+			slots:     make(chan struct{}, MaxInFlight),
+			shut:      t.closed,
+		}
+		if !t.closed {
+			t.sessions[addr] = s
+		}
+	}
+	return s
+}
+
+// open returns the session's stream, connecting first if the
+// session is not connected (and is not backing off).
+func (s *session) open() (pro.Coin_SessionClient, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stream != nil {
+		return s.stream, nil
+	}
+	if s.shut {
+		return nil, errSessionClosed
+	}
+	if time.Now().Before(s.retryAt) {
+		return nil, errBackoff
+	}
+	cc, err := s.transport.connect(s.addr)
+	if err == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		var stream pro.Coin_SessionClient
+		if stream, err = pro.NewCoinClient(cc).Session(ctx); err == nil {
+			s.cc, s.stream, s.cancel = cc, stream, cancel
+			s.backoff = 0
+			go s.receive(stream)
+			return stream, nil
+		}
+		cancel()
+		cc.Close()
+	}
+	if s.backoff == 0 {
+		s.backoff = minBackoff
+	} else if s.backoff *= 2; s.backoff > maxBackoff {
+		s.backoff = maxBackoff
+	}
+	s.retryAt = time.Now().Add(s.backoff)
+	return nil, err
+}
+
+// receive delivers the responses on a stream to the requests
+// waiting for them, until the stream breaks.
+func (s *session) receive(stream pro.Coin_SessionClient) {
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			s.close(stream)
+			return
+		}
+		s.mutex.Lock()
+		if ch := s.pending[res.Id]; ch != nil {
+			delete(s.pending, res.Id)
+			ch <- res
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// close disconnects a broken stream, and fails the requests
+// waiting on it. The next call reconnects right away.
+func (s *session) close(stream pro.Coin_SessionClient) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stream != stream {
+		return
+	}
+	s.cancel()
+	s.cc.Close()
+	s.cc, s.stream, s.cancel = nil, nil, nil
+	for id, ch := range s.pending {
+		close(ch)
+		delete(s.pending, id)
+	}
+}
+
+// shutdown disconnects the session for good, and fails the
+// requests waiting on it.
+func (s *session) shutdown() {
+	s.mutex.Lock()
+	s.shut = true
+	stream := s.stream
+	s.mutex.Unlock()
+	if stream != nil {
+		s.close(stream)
+	}
+}
+
+// call sends a request to be handled by the rpc named method,
+// and waits (up to RPCTimeout) for the response. If the
+// request is rejected with a code, the error is a *reject.Error.
+// Inputs:
+// method string the name of the rpc
+// req proto.Message the request
+// reply proto.Message the response is decoded into reply
+func (s *session) call(method string, req proto.Message, reply proto.Message) error {
+	timer := time.NewTimer(RPCTimeout)
+	defer timer.Stop()
+	select {
+	case s.slots <- struct{}{}:
+	case <-timer.C:
+		return errTimeout
+	}
+	defer func() { <-s.slots }()
+	stream, err := s.open()
+	if err != nil {
+		return err
+	}
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	ch := make(chan *pro.Envelope, 1)
+	s.mutex.Lock()
+	s.nextID++
+	id := s.nextID
+	s.pending[id] = ch
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.pending, id)
+		s.mutex.Unlock()
+	}()
+	s.sendMutex.Lock()
+	err = stream.Send(&pro.Envelope{Id: id, Method: method, Payload: payload})
+	s.sendMutex.Unlock()
+	if err != nil {
+		s.close(stream)
+		return err
+	}
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			return errSessionClosed
+		}
+		if res.Reject != nil {
+			return reject.Decode(res.Reject)
+		}
+		if res.Error != "" {
+			return errors.New(res.Error)
+		}
+		return proto.Unmarshal(res.Payload, reply)
+	case <-timer.C:
+		return errTimeout
+	}
+}
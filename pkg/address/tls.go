@@ -0,0 +1,68 @@
+package address
+
+import (
+	"Coin/pkg/id"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// TLSConfig returns the TLS configuration of a node with the
+// given id. Nodes have no certificate authority: both ends of
+// a connection present a self-signed certificate for the key
+// of their id (see id.Certificate), and the TLS handshake
+// proves that they control that key.
+func TLSConfig(i id.ID) (*tls.Config, error) {
+	cert, err := id.Certificate(i)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+		// the certificate is checked by VerifyPeerCertificate instead
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: verifyPeerCertificate,
+		MinVersion:            tls.VersionTLS12,
+	}, nil
+}
+
+// verifyPeerCertificate checks the certificate the other end of
+// a connection presented.
+func verifyPeerCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no certificate")
+	}
+	_, err := id.VerifyCertificate(rawCerts[0])
+	return err
+}
+
+// NewTLSTransport returns a Transport whose connections are
+// secured with config (see TLSConfig).
+func NewTLSTransport(config *tls.Config) *Transport {
+	return NewTransport(credentials.NewTLS(config))
+}
+
+// Identity returns the public key of the id of the node that
+// sent a request, if it connected over TLS.
+// Returns:
+// []byte the public key (see id.ID.GetPublicKeyBytes), or
+// nil if the request did not come over TLS
+func Identity(ctx context.Context) []byte {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return nil
+	}
+	key, err := id.VerifyCertificate(info.State.PeerCertificates[0].Raw)
+	if err != nil {
+		return nil
+	}
+	return key
+}
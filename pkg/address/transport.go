@@ -0,0 +1,71 @@
+package address
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"sync"
+)
+
+// Transport is how a node reaches other nodes. It keeps one
+// session to every node it sends requests to (see session),
+// until the node is dropped (see Drop) or the transport is
+// closed (see Close).
+// creds secure the connections (nil means they are insecure).
+// sessions maps addresses to their sessions.
+// closed is whether the transport was closed.
+// mutex guards sessions and closed.
+type Transport struct {
+	creds    credentials.TransportCredentials
+	sessions map[string]*session
+	closed   bool
+	mutex    sync.Mutex
+}
+
+// DefaultTransport is the insecure transport that addresses
+// without a Transport use. Nodes have transports of their own.
+var DefaultTransport = NewTransport(nil)
+
+// NewTransport returns a Transport whose connections are
+// secured with creds (or insecure, if creds is nil).
+func NewTransport(creds credentials.TransportCredentials) *Transport {
+	return &Transport{creds: creds, sessions: make(map[string]*session)}
+}
+
+// Drop closes the session to the node at addr, if there is
+// one. The next request to the node starts a new session.
+func (t *Transport) Drop(addr string) {
+	t.mutex.Lock()
+	s := t.sessions[addr]
+	delete(t.sessions, addr)
+	t.mutex.Unlock()
+	if s != nil {
+		s.shutdown()
+	}
+}
+
+// Close closes every session of the transport. Requests sent
+// over a closed transport fail.
+func (t *Transport) Close() {
+	t.mutex.Lock()
+	sessions := t.sessions
+	t.sessions = make(map[string]*session)
+	t.closed = true
+	t.mutex.Unlock()
+	for _, s := range sessions {
+		s.shutdown()
+	}
+}
+
+// connect dials a connection to the node at addr.
+func (t *Transport) connect(addr string) (*grpc.ClientConn, error) {
+	security := grpc.WithInsecure()
+	if t.creds != nil {
+		security = grpc.WithTransportCredentials(t.creds)
+	}
+	return grpc.Dial(addr, []grpc.DialOption{
+		security,
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(MaxMessageSize), grpc.MaxCallSendMsgSize(MaxMessageSize)),
+		grpc.WithUnaryInterceptor(clientUnaryInterceptor),
+	}...)
+}
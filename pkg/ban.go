@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/utils"
+	"fmt"
+	"time"
+)
+
+// errBanned is returned to banned addresses that try to become peers
+var errBanned = reject.New(pro.RejectCode_REJECT_BANNED, "", "address is banned")
+
+// misbehaving adds a Misbehavior to the score of the peer with
+// address addr (addr may be empty, or no longer a peer, in
+// which case nothing happens). A peer whose score reaches the
+// BanThreshold is disconnected and banned for BanDuration.
+func (n *Node) misbehaving(addr string, m peer.Misbehavior) {
+	p := n.PeerDb.Get(addr)
+	if p == nil {
+		return
+	}
+	score := p.AddMisbehavior(m)
+	utils.Debug.Printf("%v scored %v from %v (misbehavior score %v)",
+		utils.FmtAddr(n.Address), m, utils.FmtAddr(addr), score)
+	if score >= n.Config.BanThreshold {
+		n.BanPeer(addr, n.Config.BanDuration, fmt.Sprintf("misbehavior score %v, last %v", score, m))
+	}
+}
+
+// BanPeer bans an address for the given duration, and
+// disconnects it if it is a peer.
+// Inputs:
+// addr string the address to ban
+// duration time.Duration how long the ban lasts
+// reason string why the address is banned
+func (n *Node) BanPeer(addr string, duration time.Duration, reason string) {
+	n.BanList.Ban(addr, time.Now().Add(duration), reason)
+	n.removePeer(addr)
+	n.requestConnections()
+	utils.Debug.Printf("%v banned %v for %v: %v",
+		utils.FmtAddr(n.Address), utils.FmtAddr(addr), duration, reason)
+}
+
+// UnbanPeer lifts the ban of an address. The address may
+// become a peer again (see runConnectionManager).
+// Returns:
+// bool true if the address was banned
+func (n *Node) UnbanPeer(addr string) bool {
+	unbanned := n.BanList.Unban(addr)
+	if unbanned {
+		utils.Debug.Printf("%v unbanned %v", utils.FmtAddr(n.Address), utils.FmtAddr(addr))
+	}
+	return unbanned
+}
+
+// ListBanned returns every current ban, ordered by address.
+func (n *Node) ListBanned() []*peer.Ban {
+	return n.BanList.List()
+}
@@ -4,7 +4,7 @@ import (
 	"Coin/pkg/pro"
 	"Coin/pkg/utils"
 	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"google.golang.org/protobuf/proto"
 	"strconv"
@@ -87,8 +87,14 @@ func DecodeBlock(pb *pro.Block) *Block {
 
 // Hash returns the hash of the block (which is done via the header)
 func (b *Block) Hash() string {
+	return b.Header.Hash()
+}
+
+// Hash returns the hash of the header, which is also
+// the hash of its block.
+func (header *Header) Hash() string {
 	h := sha256.New()
-	pb := EncodeHeader(b.Header)
+	pb := EncodeHeader(header)
 	bytes, err := proto.Marshal(pb)
 	if err != nil {
 		utils.Debug.Printf("[block.Hash()] Unable to marshal block")
@@ -97,6 +103,26 @@ func (b *Block) Hash() string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// MeetsTarget returns whether the header's hash is below its
+// own difficulty target, i.e. whether its proof of work is valid.
+func (header *Header) MeetsTarget() bool {
+	return header.Hash() < header.DifficultyTarget
+}
+
+// CheckProofOfWork checks that the header's difficulty target
+// is no easier than powLimit and that its hash meets that target.
+// Returns:
+// error why the proof of work is not valid (nil if it is)
+func (header *Header) CheckProofOfWork(powLimit string) error {
+	if len(header.DifficultyTarget) != len(powLimit) || header.DifficultyTarget > powLimit {
+		return errors.New("difficulty target is easier than the limit")
+	}
+	if !header.MeetsTarget() {
+		return errors.New("hash does not meet difficulty target")
+	}
+	return nil
+}
+
 // Size returns the size of the
 // block in bytes
 func (b *Block) Size() uint32 {
@@ -108,13 +134,17 @@ func (b *Block) NameTag() string {
 	return fmt.Sprintf("%v", utils.Colorize(fmt.Sprintf("block-%v", b.Hash()[:8]), int(i)))
 }
 
-func New(previousHash string, txs []*Transaction, target string) *Block {
+// New returns a new Block on top of previousHash with the
+// given Transactions, difficulty target and timestamp (unix
+// seconds).
+func New(previousHash string, txs []*Transaction, target string, timestamp uint32) *Block {
 	return &Block{
 		Header: &Header{
 			Version:          0,
 			PreviousHash:     previousHash,
 			MerkleRoot:       CalculateMerkleRoot(txs),
 			DifficultyTarget: target,
+			Timestamp:        timestamp,
 		},
 		Transactions: txs,
 	}
@@ -131,33 +161,13 @@ func New(previousHash string, txs []*Transaction, target string) *Block {
 // string	the root of the merkle tree represented
 // as a hex string.
 func CalculateMerkleRoot(txs []*Transaction) string {
-	var hashes []string
-	if len(txs) > 1 && len(txs)%2 != 0 {
-		txs = append(txs, txs[len(txs)-1])
-	}
-	for _, t := range txs {
-		hashes = append(hashes, t.Hash())
-	}
-	for len(hashes) != 1 {
-		var newHashes []string
-		if len(hashes)%2 != 0 {
-			hashes = append(hashes, hashes[len(hashes)-1])
-		}
-		for i := 0; i < len(hashes); i += 2 {
-			bytes1, _ := hex.DecodeString(hashes[i])
-			bytes2, _ := hex.DecodeString(hashes[i+1])
-			bytes3 := append(bytes1[:], bytes2[:]...)
-			newHsh := utils.Hash(bytes3)
-			newHashes = append(newHashes, newHsh)
-		}
-		hashes = newHashes
-	}
-	if hashes == nil || len(hashes) < 1 {
+	if len(txs) == 0 {
 		fmt.Printf("ERROR {block.CaclMrkRt}: function" +
 			"ended up not being able to calculate a root.\n")
 		return ""
 	}
-	return hashes[0]
+	levels := merkleLevels(transactionHashes(txs))
+	return levels[len(levels)-1][0]
 }
 
 func (b *Block) Summarize() string {
@@ -0,0 +1,166 @@
+package block
+
+import (
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// shortIDLength is the number of hex characters in a short id.
+const shortIDLength = 12
+
+// CompactBlock is a Block without most of its Transactions. The
+// receiver rebuilds the Block from the Transactions it already has,
+// and only requests the ones it is missing.
+// Header is the Block's Header.
+// ShortIDs are the short ids of the Transactions that are not
+// prefilled, in Block order (see ShortID).
+// Prefilled maps positions in the Block to Transactions that the
+// receiver is unlikely to have, such as the coinbase.
+type CompactBlock struct {
+	Header    *Header
+	ShortIDs  []string
+	Prefilled map[uint32]*Transaction
+}
+
+// ShortID returns the short id of a Transaction in a Block. Short
+// ids are salted with the Block's hash, so that Transactions that
+// collide in one Block are unlikely to collide in another.
+func ShortID(blockHash string, txHash string) string {
+	return utils.Hash([]byte(blockHash + txHash))[:shortIDLength]
+}
+
+// NewCompactBlock returns the CompactBlock of a Block, with
+// its coinbase prefilled.
+func NewCompactBlock(b *Block) *CompactBlock {
+	hash := b.Hash()
+	cb := &CompactBlock{Header: b.Header, Prefilled: make(map[uint32]*Transaction)}
+	for i, tx := range b.Transactions {
+		if i == 0 && tx.IsCoinbase() {
+			cb.Prefilled[0] = tx
+			continue
+		}
+		cb.ShortIDs = append(cb.ShortIDs, ShortID(hash, tx.Hash()))
+	}
+	return cb
+}
+
+// Hash returns the hash of the Block the CompactBlock stands for.
+func (cb *CompactBlock) Hash() string {
+	return cb.Header.Hash()
+}
+
+// EncodeCompactBlock returns a pro.CompactBlock given a CompactBlock.
+func EncodeCompactBlock(cb *CompactBlock) *pro.CompactBlock {
+	var prefilled []*pro.PrefilledTransaction
+	for i, tx := range cb.Prefilled {
+		prefilled = append(prefilled, &pro.PrefilledTransaction{Index: i, Transaction: EncodeTransaction(tx)})
+	}
+	sort.Slice(prefilled, func(i, j int) bool { return prefilled[i].Index < prefilled[j].Index })
+	return &pro.CompactBlock{
+		Header:    EncodeHeader(cb.Header),
+		ShortIds:  cb.ShortIDs,
+		Prefilled: prefilled,
+	}
+}
+
+// DecodeCompactBlock returns a CompactBlock given a pro.CompactBlock.
+func DecodeCompactBlock(pcb *pro.CompactBlock) *CompactBlock {
+	cb := &CompactBlock{
+		Header:    DecodeHeader(pcb.GetHeader()),
+		ShortIDs:  pcb.GetShortIds(),
+		Prefilled: make(map[uint32]*Transaction),
+	}
+	for _, p := range pcb.GetPrefilled() {
+		cb.Prefilled[p.GetIndex()] = DecodeTransaction(p.GetTransaction())
+	}
+	return cb
+}
+
+// PartialBlock is a Block that is being rebuilt from a
+// CompactBlock. Transactions that are still missing are nil.
+type PartialBlock struct {
+	Header       *Header
+	Transactions []*Transaction
+}
+
+// Rebuild starts rebuilding the Block from a pool of Transactions
+// the receiver already has. Short ids that match no Transaction in
+// the pool, or more than one, are left missing.
+// Inputs:
+// pool []*Transaction the Transactions the receiver has
+// Returns:
+// *PartialBlock the Block, possibly with missing Transactions
+// error if the CompactBlock's prefilled positions are invalid
+func (cb *CompactBlock) Rebuild(pool []*Transaction) (*PartialBlock, error) {
+	n := len(cb.ShortIDs) + len(cb.Prefilled)
+	pb := &PartialBlock{Header: cb.Header, Transactions: make([]*Transaction, n)}
+	for i, tx := range cb.Prefilled {
+		if int(i) >= n {
+			return nil, fmt.Errorf("prefilled transaction at %v of %v", i, n)
+		}
+		pb.Transactions[i] = tx
+	}
+	hash := cb.Hash()
+	matches := make(map[string]*Transaction)
+	ambiguous := make(map[string]bool)
+	for _, tx := range pool {
+		id := ShortID(hash, tx.Hash())
+		if other, ok := matches[id]; ok && other.Hash() != tx.Hash() {
+			ambiguous[id] = true
+		}
+		matches[id] = tx
+	}
+	next := 0
+	for i := range pb.Transactions {
+		if pb.Transactions[i] != nil {
+			continue
+		}
+		id := cb.ShortIDs[next]
+		next++
+		if !ambiguous[id] {
+			pb.Transactions[i] = matches[id]
+		}
+	}
+	return pb, nil
+}
+
+// Missing returns the positions of the Transactions that are
+// still missing, in order.
+func (pb *PartialBlock) Missing() []uint32 {
+	var missing []uint32
+	for i, tx := range pb.Transactions {
+		if tx == nil {
+			missing = append(missing, uint32(i))
+		}
+	}
+	return missing
+}
+
+// Fill fills in the missing Transactions, which must be given in
+// the order that Missing returns their positions.
+func (pb *PartialBlock) Fill(txs []*Transaction) error {
+	missing := pb.Missing()
+	if len(txs) != len(missing) {
+		return fmt.Errorf("got %v transactions for %v missing", len(txs), len(missing))
+	}
+	for i, index := range missing {
+		pb.Transactions[index] = txs[i]
+	}
+	return nil
+}
+
+// Block returns the rebuilt Block. It fails if Transactions are
+// still missing, or if the Transactions do not match the Header's
+// merkle root (for example, because two short ids collided).
+func (pb *PartialBlock) Block() (*Block, error) {
+	if len(pb.Missing()) > 0 {
+		return nil, errors.New("block still has missing transactions")
+	}
+	if CalculateMerkleRoot(pb.Transactions) != pb.Header.MerkleRoot {
+		return nil, errors.New("rebuilt transactions do not match the merkle root")
+	}
+	return &Block{Header: pb.Header, Transactions: pb.Transactions}, nil
+}
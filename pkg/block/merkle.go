@@ -0,0 +1,126 @@
+package block
+
+import (
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"encoding/hex"
+	"errors"
+)
+
+// MerkleProof is a compact merkle branch that proves a Transaction
+// is part of a Block without needing the rest of the Block.
+// TransactionHash is the hash of the Transaction being proven.
+// Index is the position of the Transaction in the Block.
+// Hashes are the sibling hashes on the path from the Transaction
+// up to (but not including) the merkle root, starting at the leaves.
+type MerkleProof struct {
+	TransactionHash string
+	Index           uint32
+	Hashes          []string
+}
+
+// EncodeMerkleProof returns a pro.MerkleProof given a MerkleProof.
+func EncodeMerkleProof(mp *MerkleProof) *pro.MerkleProof {
+	return &pro.MerkleProof{
+		TransactionHash: mp.TransactionHash,
+		Index:           mp.Index,
+		Hashes:          mp.Hashes,
+	}
+}
+
+// DecodeMerkleProof returns a MerkleProof given a pro.MerkleProof.
+func DecodeMerkleProof(pmp *pro.MerkleProof) *MerkleProof {
+	return &MerkleProof{
+		TransactionHash: pmp.GetTransactionHash(),
+		Index:           pmp.GetIndex(),
+		Hashes:          pmp.GetHashes(),
+	}
+}
+
+// NewMerkleProof builds the merkle branch for the Transaction
+// with hash txHash in txs.
+// Inputs:
+// txs	[]*Transaction the Transactions of a Block, in order
+// txHash	string the hash of the Transaction to prove
+// Returns:
+// *MerkleProof	the proof
+// error	if the Transaction is not in txs
+func NewMerkleProof(txs []*Transaction, txHash string) (*MerkleProof, error) {
+	hashes := transactionHashes(txs)
+	index := -1
+	for i, h := range hashes {
+		if h == txHash {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, errors.New("transaction is not in block")
+	}
+	mp := &MerkleProof{TransactionHash: txHash, Index: uint32(index)}
+	levels := merkleLevels(hashes)
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			// the last node of an odd level is paired with itself
+			sibling = index
+		}
+		mp.Hashes = append(mp.Hashes, level[sibling])
+		index /= 2
+	}
+	return mp, nil
+}
+
+// Verify returns whether the proof shows that its Transaction
+// is part of a Block with the given merkle root.
+func (mp *MerkleProof) Verify(merkleRoot string) bool {
+	h := mp.TransactionHash
+	index := mp.Index
+	for _, sibling := range mp.Hashes {
+		if index%2 == 0 {
+			h = hashPair(h, sibling)
+		} else {
+			h = hashPair(sibling, h)
+		}
+		index /= 2
+	}
+	return index == 0 && h == merkleRoot
+}
+
+// transactionHashes returns the hashes of txs, in order.
+func transactionHashes(txs []*Transaction) []string {
+	hashes := make([]string, 0, len(txs))
+	for _, t := range txs {
+		hashes = append(hashes, t.Hash())
+	}
+	return hashes
+}
+
+// merkleLevels returns every level of the merkle tree built from
+// the leaf hashes, starting with the leaves and ending with a
+// level containing only the root. When a level has an odd number
+// of nodes, the last node is paired with itself.
+func merkleLevels(leaves []string) [][]string {
+	levels := [][]string{leaves}
+	hashes := leaves
+	for len(hashes) > 1 {
+		var newHashes []string
+		for i := 0; i < len(hashes); i += 2 {
+			right := hashes[i]
+			if i+1 < len(hashes) {
+				right = hashes[i+1]
+			}
+			newHashes = append(newHashes, hashPair(hashes[i], right))
+		}
+		levels = append(levels, newHashes)
+		hashes = newHashes
+	}
+	return levels
+}
+
+// hashPair returns the hash of two concatenated hex hashes.
+func hashPair(left, right string) string {
+	bytes1, _ := hex.DecodeString(left)
+	bytes2, _ := hex.DecodeString(right)
+	return utils.Hash(append(bytes1, bytes2...))
+}
@@ -5,6 +5,8 @@ import (
 	"Coin/pkg/pro"
 	"Coin/pkg/utils"
 	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"google.golang.org/protobuf/proto"
 	"strconv"
@@ -26,7 +28,7 @@ type TransactionInput struct {
 // Recall that TransactionOutputs generate TransactionInputs which in turn
 // generate new TransactionOutputs and so forth.
 // Amount is how much this TransactionOutput is worth.
-// LockingScript is the publicKey of the payee which can be verified by the payee's signature.
+// LockingScript is the publicKey of the payee (in hex) which can be verified by the payee's signature.
 type TransactionOutput struct {
 	Amount        uint32
 	LockingScript string
@@ -124,7 +126,7 @@ func (tx *Transaction) Hash() string {
 	pt := EncodeTransaction(tx)
 	bytes, err := proto.Marshal(pt)
 	if err != nil {
-		fmt.Errorf("[tx.Hash()] Unable to marshal transaction")
+		utils.Debug.Printf("[tx.Hash()] Unable to marshal transaction")
 	}
 	h.Write(bytes)
 	return fmt.Sprintf("%x", h.Sum(nil))
@@ -163,25 +165,39 @@ func (tx *Transaction) NameTag() string {
 	return fmt.Sprintf("%v", utils.Colorize(fmt.Sprintf("tx-%v", tx.Hash()[:6]), int(i)))
 }
 
+// SignatureHash returns what the inputs of a transaction
+// sign: the hash of the transaction with empty unlocking
+// scripts, since a signature can not sign itself.
+func (tx *Transaction) SignatureHash() []byte {
+	unsigned := &Transaction{Version: tx.Version, Outputs: tx.Outputs, LockTime: tx.LockTime}
+	for _, txi := range tx.Inputs {
+		unsigned.Inputs = append(unsigned.Inputs, &TransactionInput{
+			ReferenceTransactionHash: txi.ReferenceTransactionHash,
+			OutputIndex:              txi.OutputIndex,
+		})
+	}
+	h, _ := hex.DecodeString(unsigned.Hash())
+	return h
+}
+
 // MakeSignature generates
 // an unlocking script (a.k.a. signature) for the
-// transaction output based on a private key.
+// transaction output based on a private key, which
+// lets tx spend the output. The signature covers all
+// of tx (see SignatureHash), so it can not be reused
+// by another transaction.
 // Inputs:
 // id	id.ID	the id of the person wanting to
 // unlock the particular transaction output.
+// tx	*Transaction	the transaction that spends
+// the output.
 // Returns:
 // string	The signature represented as a hex string.
 // error	Errors if the signature could not be
 // produced or there was a decoding error.
-func (txo *TransactionOutput) MakeSignature(id id.ID) (string, error) {
+func (txo *TransactionOutput) MakeSignature(id id.ID, tx *Transaction) (string, error) {
 	sk := id.GetPrivateKey()
-	// convert txo to bytes
-	ptxo := EncodeTransactionOutput(txo)
-	bytes, err := proto.Marshal(ptxo)
-	if err != nil {
-		fmt.Errorf("[tx.MakeSignature()] Unable to marshal transaction")
-	}
-	sig, err := utils.Sign(sk, bytes)
+	sig, err := utils.Sign(sk, tx.SignatureHash())
 	if err != nil {
 		fmt.Printf("ERROR {TransactionOutput.MakeSignature}: " +
 			"The signature could not be formed.\n")
@@ -189,3 +205,29 @@ func (txo *TransactionOutput) MakeSignature(id id.ID) (string, error) {
 	}
 	return sig, nil
 }
+
+// CheckSignature checks that an unlocking script
+// lets tx spend the transaction output: it has to
+// be a signature of tx (see MakeSignature) by the
+// key in the output's locking script.
+// Inputs:
+// tx	*Transaction	the transaction that spends
+// the output.
+// sig	string	the unlocking script.
+// Returns:
+// error	why the unlocking script does not
+// unlock the output (nil if it does).
+func (txo *TransactionOutput) CheckSignature(tx *Transaction, sig string) error {
+	pkB, err := hex.DecodeString(txo.LockingScript)
+	if err != nil {
+		return fmt.Errorf("locking script is not a public key: %v", err)
+	}
+	pk, err := utils.Byt2PK(pkB)
+	if err != nil {
+		return fmt.Errorf("locking script is not a public key: %v", err)
+	}
+	if !utils.Verify(pk, tx.SignatureHash(), sig) {
+		return errors.New("unlocking script is not a signature by the coin's owner")
+	}
+	return nil
+}
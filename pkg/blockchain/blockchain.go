@@ -6,7 +6,9 @@ import (
 	"Coin/pkg/blockchain/chainwriter"
 	"Coin/pkg/blockchain/coindatabase"
 	"Coin/pkg/utils"
-	"math"
+	"errors"
+	"fmt"
+	"sort"
 )
 
 // BlockChain is the main type of this project.
@@ -20,7 +22,10 @@ import (
 // BlockInfoDB is a pointer to a block info database
 // ChainWriter is a pointer to a chain writer.
 // CoinDB is a pointer to a coin database.
-//TODO: blockchain has to confirm block and also has to listen
+// genesisHash is the hash of the genesis block.
+// medianTimeSpan is the number of blocks used for the median time past.
+// powLimit is the easiest difficulty target a block may have.
+// TODO: blockchain has to confirm block and also has to listen
 // for when the miner needs to sum inputs
 type BlockChain struct {
 	Address      string
@@ -34,6 +39,10 @@ type BlockChain struct {
 	BlockInfoDB *blockinfodatabase.BlockInfoDatabase
 	ChainWriter *chainwriter.ChainWriter
 	CoinDB      *coindatabase.CoinDatabase
+
+	genesisHash    string
+	medianTimeSpan uint32
+	powLimit       string
 }
 
 // New returns a blockchain given a Config.
@@ -59,6 +68,10 @@ func New(config *Config) *BlockChain {
 		BlockInfoDB:  blockinfodatabase.New(blockInfoDBConfig),
 		ChainWriter:  chainwriter.New(chainWriterConfig),
 		CoinDB:       coindatabase.New(coinDBConfig),
+
+		genesisHash:    hash,
+		medianTimeSpan: config.MedianTimeSpan,
+		powLimit:       config.POWLimit,
 	}
 	// have to store the genesis block
 	bc.CoinDB.StoreBlock(genBlock.Transactions)
@@ -94,19 +107,30 @@ func GenesisBlock(config *Config) *block.Block {
 	}
 }
 
+// Update is how handling a Block changed the active chain.
+// Connected are the Blocks that joined the active chain, oldest
+// first.
+// Disconnected are the Blocks that left it, newest first.
+type Update struct {
+	Connected    []*block.Block
+	Disconnected []*block.Block
+}
+
 // HandleBlock handles a new Block. At a high level, it:
 // (1) Validates and stores the Block.
 // (2) Stores the Block and resulting Undoblock to Disk.
 // (3) Stores the BlockRecord in the BlockInfoDatabase.
 // (4) Handles a fork, if necessary.
 // (5) Updates the BlockChain's fields.
-func (bc *BlockChain) HandleBlock(b *block.Block) {
+// Returns:
+// *Update how the active chain changed (nil if it did not)
+func (bc *BlockChain) HandleBlock(b *block.Block) *Update {
 	appends := bc.appendsToActiveChain(b)
 	blockHash := b.Hash()
 
 	// 1. Validate Block
 	if appends && !bc.CoinDB.ValidateBlock(b.Transactions) {
-		return
+		return nil
 	}
 
 	// 2. Make Undo Block
@@ -132,50 +156,55 @@ func (bc *BlockChain) HandleBlock(b *block.Block) {
 			bc.UnsafeHashes = bc.UnsafeHashes[1:]
 		}
 		bc.UnsafeHashes = append(bc.UnsafeHashes, blockHash)
+		return &Update{Connected: []*block.Block{b}}
 	} else if height > bc.Length {
 		// 8. Handle fork
-		bc.handleFork(b, height)
+		return bc.handleFork(b, height)
 	}
+	return nil
 }
 
 // handleFork updates the BlockChain when a fork occurs. First, it
 // finds the Blocks the BlockChain must revert. Once found, it uses
 // those Blocks to update the CoinDatabase. Lastly, it updates the
 // BlockChain's fields to reflect the fork.
-func (bc *BlockChain) handleFork(b *block.Block, height uint32) {
+// Returns:
+// *Update the reverted and the new Blocks (nil if the fork
+// was invalid)
+func (bc *BlockChain) handleFork(b *block.Block, height uint32) *Update {
 	// (1) Make sure that this is a valid fork
-	forkLength, ancestorHash := bc.getForkLengthAndAncestor(b.Hash())
-	if forkLength < 0 {
+	forkBlocks, ancestorHash := bc.getForkBlocksAndAncestor(b)
+	if forkBlocks == nil {
 		utils.Debug.Printf("[blockchain.handleFork] fork was invalid")
-		return
+		return nil
 	}
+	ancestorHeight := height - uint32(len(forkBlocks))
 
 	// (2) retrieve the blocks on the existing main chain
-	blocks, undoBlocks := bc.getBlocksAndUndoBlocks(forkLength, bc.LastHash)
+	blocks, undoBlocks := bc.getBlocksAndUndoBlocks(int(bc.Length-ancestorHeight), bc.LastHash)
 
 	// (3) update unsafe hashes
-	min := int(math.Min(float64(6), float64(forkLength)))
 	// delete unsafe hashes up until the common ancestor
-	for i := min - 1; i > 0; i-- {
-		if bc.UnsafeHashes[i] == ancestorHash {
-			break
-		} else {
-			bc.UnsafeHashes = bc.UnsafeHashes[:len(bc.UnsafeHashes)-1]
-		}
+	for bc.UnsafeHashes[len(bc.UnsafeHashes)-1] != ancestorHash {
+		bc.UnsafeHashes = bc.UnsafeHashes[:len(bc.UnsafeHashes)-1]
 	}
-	// add in the new hashes (reverse order because that's how getBlocksAndUndoBlocks
-	// returns them)
-	for i := len(blocks) - 1; i >= 0; i-- {
-		bc.UnsafeHashes = append(bc.UnsafeHashes, blocks[i].Hash())
+	// add in the new hashes (reverse order because that's how
+	// getForkBlocksAndAncestor returns them)
+	for i := len(forkBlocks) - 1; i >= 0; i-- {
+		bc.UnsafeHashes = append(bc.UnsafeHashes, forkBlocks[i].Hash())
+	}
+	if len(bc.UnsafeHashes) > bc.maxHashes {
+		bc.UnsafeHashes = bc.UnsafeHashes[len(bc.UnsafeHashes)-bc.maxHashes:]
 	}
 
 	// (4) Reflect changes in coinDB
 	bc.CoinDB.UndoCoins(blocks, undoBlocks)
 
 	// (5) Store our new blocks in the coinDB!
-	for _, bl := range blocks {
+	for i := len(forkBlocks) - 1; i >= 0; i-- {
+		bl := forkBlocks[i]
 		if !bc.CoinDB.ValidateBlock(bl.Transactions) {
-			utils.Debug.Printf("Validation failed for forked block {%v}", b.Hash())
+			utils.Debug.Printf("Validation failed for forked block {%v}", bl.Hash())
 		}
 		bc.CoinDB.StoreBlock(bl.Transactions)
 	}
@@ -184,16 +213,23 @@ func (bc *BlockChain) handleFork(b *block.Block, height uint32) {
 	bc.LastBlock = b
 	bc.LastHash = b.Hash()
 	bc.Length = height
+	return &Update{Connected: reverseBlocks(forkBlocks), Disconnected: blocks}
 }
 
 // makeUndoBlock returns an UndoBlock given a slice of Transactions.
+// Inputs that spend outputs created earlier in the same slice are
+// skipped, since undoing the block removes those outputs anyway.
 func (bc *BlockChain) makeUndoBlock(txs []*block.Transaction) *chainwriter.UndoBlock {
 	var transactionHashes []string
 	var outputIndexes []uint32
 	var amounts []uint32
 	var lockingScripts []string
+	created := make(map[string]bool)
 	for _, tx := range txs {
 		for _, txi := range tx.Inputs {
+			if created[txi.ReferenceTransactionHash] {
+				continue
+			}
 			cl := coindatabase.CoinLocator{
 				ReferenceTransactionHash: txi.ReferenceTransactionHash,
 				OutputIndex:              txi.OutputIndex,
@@ -213,6 +249,7 @@ func (bc *BlockChain) makeUndoBlock(txs []*block.Transaction) *chainwriter.UndoB
 			amounts = append(amounts, coin.TransactionOutput.Amount)
 			lockingScripts = append(lockingScripts, coin.TransactionOutput.LockingScript)
 		}
+		created[tx.Hash()] = true
 	}
 	return &chainwriter.UndoBlock{
 		TransactionInputHashes: transactionHashes,
@@ -223,8 +260,11 @@ func (bc *BlockChain) makeUndoBlock(txs []*block.Transaction) *chainwriter.UndoB
 }
 
 // GetBlock uses the ChainWriter to retrieve a Block from Disk
-// given that Block's hash
+// given that Block's hash. It returns nil if the Block is unknown.
 func (bc *BlockChain) GetBlock(blockHash string) *block.Block {
+	if !bc.BlockInfoDB.HasBlockRecord(blockHash) {
+		return nil
+	}
 	br := bc.BlockInfoDB.GetBlockRecord(blockHash)
 	fi := &chainwriter.FileInfo{
 		FileName:    br.BlockFile,
@@ -235,9 +275,13 @@ func (bc *BlockChain) GetBlock(blockHash string) *block.Block {
 }
 
 // getUndoBlock uses the ChainWriter to retrieve an UndoBlock
-// from Disk given the corresponding Block's hash
+// from Disk given the corresponding Block's hash. Empty UndoBlocks
+// are never written to Disk (see ChainWriter.StoreBlock).
 func (bc *BlockChain) getUndoBlock(blockHash string) *chainwriter.UndoBlock {
 	br := bc.BlockInfoDB.GetBlockRecord(blockHash)
+	if br.UndoFile == "" {
+		return &chainwriter.UndoBlock{}
+	}
 	fi := &chainwriter.FileInfo{
 		FileName:    br.UndoFile,
 		StartOffset: br.UndoStartOffset,
@@ -298,35 +342,170 @@ func (bc *BlockChain) GetHashes(start, end uint32) []string {
 	return reverseHashes(hashes)
 }
 
+// GetHeaders returns up to max headers of the main chain, starting
+// right after the first hash in locator that is on the main chain
+// and stopping early at stopHash (inclusive) if it is given. If no
+// hash in locator is on the main chain, the headers start right
+// after the genesis block.
+func (bc *BlockChain) GetHeaders(locator []string, stopHash string, max uint32) []*block.Header {
+	mainHashes := bc.GetHashes(1, bc.Length)
+	indexes := make(map[string]int)
+	for i, h := range mainHashes {
+		indexes[h] = i
+	}
+	start := 0
+	for _, h := range locator {
+		if i, ok := indexes[h]; ok {
+			start = i
+			break
+		}
+	}
+	var headers []*block.Header
+	for i := start + 1; i < len(mainHashes) && uint32(len(headers)) < max; i++ {
+		headers = append(headers, bc.BlockInfoDB.GetBlockRecord(mainHashes[i]).Header)
+		if mainHashes[i] == stopHash {
+			break
+		}
+	}
+	return headers
+}
+
+// Locator returns hashes of blocks on the active chain, newest
+// first, that a peer can use to find where our chain and its
+// chain split (see GetHeaders).
+func (bc *BlockChain) Locator() []string {
+	return locator(bc.BlockInfoDB, bc.LastHash, bc.genesisHash)
+}
+
+// CheckHeaders validates a run of headers that a peer claims
+// extends a Block we already have. The run is valid if:
+// (1) the first header's previous Block is known,
+// (2) every other header links to the header before it,
+// (3) every header has valid proof of work (see HeaderChain),
+// (4) every header's timestamp is above the median time past
+// of the headers (and Blocks) before it.
+// A run that arrives in batches is checked one batch at a time:
+// the headers of the earlier batches are only used to find where
+// the new batch starts, and are not checked again.
+// Inputs:
+// checked []*block.Header the headers of the run that were
+// already checked, or nil if headers starts the run
+// headers []*block.Header the headers to check
+// Returns:
+// uint32 the height the last header of the run would have
+// error if the run is invalid
+func (bc *BlockChain) CheckHeaders(checked []*block.Header, headers []*block.Header) (uint32, error) {
+	if len(headers) == 0 {
+		return 0, errors.New("[BlockChain.CheckHeaders] no headers")
+	}
+	start := headers[0]
+	if len(checked) > 0 {
+		start = checked[0]
+	}
+	if !bc.BlockInfoDB.HasBlockRecord(start.PreviousHash) {
+		return 0, errors.New("[BlockChain.CheckHeaders] first header does not connect to a known block")
+	}
+	height := bc.BlockInfoDB.GetBlockRecord(start.PreviousHash).Height + uint32(len(checked))
+	// timestamps of the medianTimeSpan most recent ancestors, oldest first
+	var timestamps []uint32
+	for i := len(checked) - 1; i >= 0 && uint32(len(timestamps)) < bc.medianTimeSpan; i-- {
+		timestamps = append([]uint32{checked[i].Timestamp}, timestamps...)
+	}
+	for nextHash := start.PreviousHash; nextHash != "" && uint32(len(timestamps)) < bc.medianTimeSpan; {
+		br := bc.BlockInfoDB.GetBlockRecord(nextHash)
+		timestamps = append([]uint32{br.Header.Timestamp}, timestamps...)
+		nextHash = br.Header.PreviousHash
+	}
+	prevHash := start.PreviousHash
+	if len(checked) > 0 {
+		prevHash = checked[len(checked)-1].Hash()
+	}
+	for i, h := range headers {
+		i := i + len(checked)
+		if h.PreviousHash != prevHash {
+			return 0, fmt.Errorf("[BlockChain.CheckHeaders] header %v does not link to the one before it", i)
+		}
+		if err := h.CheckProofOfWork(bc.powLimit); err != nil {
+			return 0, fmt.Errorf("[BlockChain.CheckHeaders] header %v: %v", i, err)
+		}
+		if h.Timestamp <= median(timestamps) {
+			return 0, fmt.Errorf("[BlockChain.CheckHeaders] header %v is not above the median time past", i)
+		}
+		timestamps = append(timestamps, h.Timestamp)
+		if uint32(len(timestamps)) > bc.medianTimeSpan {
+			timestamps = timestamps[1:]
+		}
+		prevHash = h.Hash()
+		height++
+	}
+	return height, nil
+}
+
+// MedianTimePast returns the median timestamp of the last
+// medianTimeSpan blocks ending at (and including) the block
+// with the given hash. A new block on top of that block must
+// have a timestamp strictly greater than this value.
+func (bc *BlockChain) MedianTimePast(hash string) uint32 {
+	return medianTimePast(bc.BlockInfoDB, hash, bc.medianTimeSpan)
+}
+
+// medianTimePast returns the median timestamp of the last span
+// BlockRecords in blockInfoDB ending at the one with the given hash.
+func medianTimePast(blockInfoDB *blockinfodatabase.BlockInfoDatabase, hash string, span uint32) uint32 {
+	var timestamps []uint32
+	nextHash := hash
+	for i := uint32(0); i < span && blockInfoDB.HasBlockRecord(nextHash); i++ {
+		br := blockInfoDB.GetBlockRecord(nextHash)
+		timestamps = append(timestamps, br.Header.Timestamp)
+		nextHash = br.Header.PreviousHash
+	}
+	return median(timestamps)
+}
+
+// median returns the median of timestamps, or 0 if there are none.
+// It does not modify timestamps.
+func median(timestamps []uint32) uint32 {
+	if len(timestamps) == 0 {
+		return 0
+	}
+	sorted := append([]uint32(nil), timestamps...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
 // appendsToActiveChain returns whether a Block appends to the
 // BlockChain's active chain or not.
 func (bc *BlockChain) appendsToActiveChain(b *block.Block) bool {
 	return bc.LastBlock.Hash() == b.Header.PreviousHash
 }
 
-// getForkLength returns the length of a fork, exclusive of the
-// common ancestor with the main chain. If it returns -1, the fork is
-// invalid
-func (bc *BlockChain) getForkLengthAndAncestor(startHash string) (int, string) {
+// getForkBlocksAndAncestor returns the Blocks on the fork ending at
+// Block b that are not on the main chain, newest first, along with
+// the hash of the fork's common ancestor with the main chain. The
+// ancestor must be one of the unsafe hashes, since only those Blocks
+// may be reverted. If it is not, the returned Blocks are nil.
+func (bc *BlockChain) getForkBlocksAndAncestor(b *block.Block) ([]*block.Block, string) {
 	// turn unsafeHashes into map for easier checking
 	unsafeHashes := make(map[string]bool)
 	for _, h := range bc.UnsafeHashes {
 		unsafeHashes[h] = true
 	}
-	forkLength := 0
-	nextHash := startHash
+	lowestUnsafeHeight := bc.Length - uint32(len(bc.UnsafeHashes)) + 1
+	blocks := []*block.Block{b}
+	nextHash := b.Header.PreviousHash
 	// keep going backwards on the forked chain until we find the common ancestor
-	// with the main chain. Update forkLength as we go
-	for i := 0; i < len(bc.UnsafeHashes)+1; i++ {
-		if _, ok := unsafeHashes[nextHash]; ok {
-			return forkLength, nextHash
+	// with the main chain.
+	for !unsafeHashes[nextHash] {
+		if !bc.BlockInfoDB.HasBlockRecord(nextHash) ||
+			bc.BlockInfoDB.GetBlockRecord(nextHash).Height <= lowestUnsafeHeight {
+			// Couldn't find an ancestor hash, so invalid fork
+			return nil, ""
 		}
-		br := bc.BlockInfoDB.GetBlockRecord(nextHash)
-		nextHash = br.Header.PreviousHash
-		forkLength++
+		bl := bc.GetBlock(nextHash)
+		blocks = append(blocks, bl)
+		nextHash = bl.Header.PreviousHash
 	}
-	// Couldn't find an ancestor hash, so invalid fork
-	return -1, ""
+	return blocks, nextHash
 }
 
 // getBlocksAndUndoBlocks returns a slice of n Blocks with a
@@ -36,7 +36,7 @@ func (blockInfoDB *BlockInfoDatabase) StoreBlockRecord(hash string, blockRecord
 	bytes, err := proto.Marshal(protoRecord)
 	// checking that the marshalling process didn't throw an error
 	if err != nil {
-		utils.Debug.Printf("Failed to marshal protoRecord:", err)
+		utils.Debug.Printf("Failed to marshal protoRecord: %v", err)
 	}
 	// attempting to store the bytes in our database AND checking to make
 	// sure that the storing process doesn't fail. The Put(key, value, writeOptions)
@@ -68,12 +68,23 @@ func (blockInfoDB *BlockInfoDatabase) GetBlockRecord(hash string) *BlockRecord {
 	// protobuf object created on line 66. Checking that the conversion process
 	// from bytes to protobuf object succeeds.
 	if err = proto.Unmarshal(data, protoRecord); err != nil {
-		utils.Debug.Printf("Failed to unmarshal record from hash {%v}:", hash, err)
+		utils.Debug.Printf("Failed to unmarshal record from hash {%v}: %v", hash, err)
 	}
 	// convert the protobuf record to a normal blockRecord and returning that.
 	return DecodeBlockRecord(protoRecord)
 }
 
+// HasBlockRecord returns whether the BlockInfoDatabase has a
+// BlockRecord for the given block hash.
+func (blockInfoDB *BlockInfoDatabase) HasBlockRecord(hash string) bool {
+	has, err := blockInfoDB.db.Has([]byte(hash), nil)
+	if err != nil {
+		utils.Debug.Printf("Unable to check for block record for hash {%v}", hash)
+		return false
+	}
+	return has
+}
+
 // Close is used to actually shut down the db (for testing purposes)
 func (blockInfoDB *BlockInfoDatabase) Close() {
 	blockInfoDB.db.Close()
@@ -4,8 +4,8 @@ import (
 	"Coin/pkg/block"
 	"Coin/pkg/blockchain/chainwriter"
 	"Coin/pkg/pro"
+	"Coin/pkg/reject"
 	"Coin/pkg/utils"
-	"fmt"
 	"github.com/syndtr/goleveldb/leveldb"
 	"google.golang.org/protobuf/proto"
 )
@@ -37,41 +37,104 @@ func New(config *Config) *CoinDatabase {
 	}
 }
 
-// ValidateBlock returns whether a Block's Transactions are valid.
+// ValidateBlock returns whether a Block's Transactions are valid
+// (see CheckBlock).
 func (coinDB *CoinDatabase) ValidateBlock(transactions []*block.Transaction) bool {
+	if err := coinDB.CheckBlock(transactions); err != nil {
+		utils.Debug.Printf("%v", err)
+		return false
+	}
+	return true
+}
+
+// CheckBlock checks whether a Block's Transactions are valid.
+// Transactions are validated in order, so a Transaction may spend
+// an output created by an earlier Transaction in the same Block,
+// but no two Transactions in the Block may spend the same Coin.
+// Returns:
+// error why the Transactions are not valid, with a reject code
+// (nil if they are valid)
+func (coinDB *CoinDatabase) CheckBlock(transactions []*block.Transaction) error {
+	// spent and created track the Coins spent and created by the
+	// Transactions of this Block that have been validated so far.
+	spent := make(map[CoinLocator]bool)
+	created := make(map[CoinLocator]bool)
 	for _, tx := range transactions {
-		if err := coinDB.ValidateTransaction(tx); err != nil {
-			utils.Debug.Printf("%v", err)
-			return false
+		if err := coinDB.validateTransactionInBlock(tx, spent, created); err != nil {
+			return err
 		}
 	}
-	return true
+	return nil
 }
 
-// ValidateTransaction checks whether a Transaction's inputs are valid Coins.
-// If the Coins have already been spent or do not exist, validateTransaction
-// returns an error.
-func (coinDB *CoinDatabase) ValidateTransaction(transaction *block.Transaction) error {
+// validateTransactionInBlock checks whether a Transaction's inputs are
+// valid Coins, given the Coins already spent and created by earlier
+// Transactions in the same Block. If the Transaction is valid, its
+// inputs are added to spent and its outputs to created.
+func (coinDB *CoinDatabase) validateTransactionInBlock(transaction *block.Transaction, spent map[CoinLocator]bool, created map[CoinLocator]bool) error {
 	for _, txi := range transaction.Inputs {
 		key := makeCoinLocator(txi)
-		if coin, ok := coinDB.mainCache[key]; ok {
-			if coin.IsSpent {
-				return fmt.Errorf("[validateTransaction] coin already spent")
-			}
-			continue
+		if spent[key] {
+			return reject.New(pro.RejectCode_REJECT_DOUBLE_SPEND, transaction.Hash(), "[validateBlock] coin spent twice within block")
 		}
-		if data, err := coinDB.db.Get([]byte(txi.ReferenceTransactionHash), nil); err != nil {
-			return fmt.Errorf("[validateTransaction] coin not in leveldb")
-		} else {
-			pcr := &pro.CoinRecord{}
-			if err2 := proto.Unmarshal(data, pcr); err2 != nil {
-				utils.Debug.Printf("Failed to unmarshal record from hash {%v}:", txi.ReferenceTransactionHash, err)
-			}
-			cr := DecodeCoinRecord(pcr)
-			if !contains(cr.OutputIndexes, txi.OutputIndex) {
-				return fmt.Errorf("[validateTransaction] coinRecord did not contain Coin")
+		if !created[key] {
+			if err := coinDB.validateInput(txi); err != nil {
+				return reject.Of(err, transaction.Hash())
 			}
 		}
+		spent[key] = true
+	}
+	txHash := transaction.Hash()
+	for i := range transaction.Outputs {
+		created[CoinLocator{ReferenceTransactionHash: txHash, OutputIndex: uint32(i)}] = true
+	}
+	return nil
+}
+
+// ValidateTransaction checks whether a Transaction's inputs are valid Coins.
+// If the Coins have already been spent or do not exist, validateTransaction
+// returns an error with a reject code.
+func (coinDB *CoinDatabase) ValidateTransaction(transaction *block.Transaction) error {
+	return coinDB.validateTransactionInBlock(transaction, make(map[CoinLocator]bool), make(map[CoinLocator]bool))
+}
+
+// Coin returns the unspent TransactionOutput that a TransactionInput
+// spends (see validation.Coins).
+// Returns:
+// *block.TransactionOutput the output
+// error why there is no such unspent output, with a reject code
+func (coinDB *CoinDatabase) Coin(txi *block.TransactionInput) (*block.TransactionOutput, error) {
+	if err := coinDB.validateInput(txi); err != nil {
+		return nil, err
+	}
+	coin := coinDB.GetCoin(makeCoinLocator(txi))
+	if coin == nil {
+		return nil, reject.New(pro.RejectCode_REJECT_MISSING_INPUTS, "", "[Coin] coin does not exist")
+	}
+	return coin.TransactionOutput, nil
+}
+
+// validateInput checks whether a TransactionInput refers to a valid,
+// unspent Coin in the CoinDatabase.
+func (coinDB *CoinDatabase) validateInput(txi *block.TransactionInput) error {
+	key := makeCoinLocator(txi)
+	if coin, ok := coinDB.mainCache[key]; ok {
+		if coin.IsSpent {
+			return reject.New(pro.RejectCode_REJECT_DOUBLE_SPEND, "", "[validateTransaction] coin already spent")
+		}
+		return nil
+	}
+	data, err := coinDB.db.Get([]byte(txi.ReferenceTransactionHash), nil)
+	if err != nil {
+		return reject.New(pro.RejectCode_REJECT_MISSING_INPUTS, "", "[validateTransaction] coin not in leveldb")
+	}
+	pcr := &pro.CoinRecord{}
+	if err2 := proto.Unmarshal(data, pcr); err2 != nil {
+		utils.Debug.Printf("Failed to unmarshal record from hash {%v}: %v", txi.ReferenceTransactionHash, err2)
+	}
+	cr := DecodeCoinRecord(pcr)
+	if !contains(cr.OutputIndexes, txi.OutputIndex) {
+		return reject.New(pro.RejectCode_REJECT_MISSING_INPUTS, "", "[validateTransaction] coinRecord did not contain Coin")
 	}
 	return nil
 }
@@ -198,18 +261,24 @@ func (coinDB *CoinDatabase) FlushMainCache() {
 	}
 }
 
-// StoreBlock handles storing a newly minted Block. It:
+// StoreBlock handles storing a newly minted Block. For each of the
+// Block's Transactions, in order, it:
 // (1) removes spent TransactionOutputs
 // (2) stores new TransactionOutputs as Coins in the mainCache
 // (3) stores CoinRecords for the Transactions in the db.
+// Handling the Transactions one at a time means a Transaction can
+// spend a Coin created earlier in the same Block.
 //
 // Important note: students do NOT have these helper functions. We created them to
 // make our lives easier. You should PUSH students to do the same, but they don't
 // have to.
 func (coinDB *CoinDatabase) StoreBlock(transactions []*block.Transaction) {
-	coinDB.updateSpentCoins(transactions)
-	coinDB.storeTransactionsInMainCache(transactions)
-	coinDB.storeTransactionsInDB(transactions)
+	for _, tx := range transactions {
+		txs := []*block.Transaction{tx}
+		coinDB.updateSpentCoins(txs)
+		coinDB.storeTransactionsInMainCache(txs)
+		coinDB.storeTransactionsInDB(txs)
+	}
 }
 
 // updateSpentCoins marks Coins in the mainCache as spent and removes
@@ -230,10 +299,8 @@ func (coinDB *CoinDatabase) updateSpentCoins(transactions []*block.Transaction)
 			} else {
 				// if the coin is not in the cache,
 				// we have to remove the coin from the
-				// database.
-				txHash := tx.Hash()
-				// remove the spent coin from the db
-				coinDB.removeCoinFromDB(txHash, cl)
+				// record of the transaction that created it.
+				coinDB.removeCoinFromDB(cl.ReferenceTransactionHash, cl)
 			}
 		}
 	}
@@ -363,7 +430,7 @@ func (coinDB *CoinDatabase) getCoinRecordFromDB(txHash string) *CoinRecord {
 	} else {
 		pcr := &pro.CoinRecord{}
 		if err = proto.Unmarshal(data, pcr); err != nil {
-			utils.Debug.Printf("Failed to unmarshal record from hash {%v}:", txHash, err)
+			utils.Debug.Printf("Failed to unmarshal record from hash {%v}: %v", txHash, err)
 		}
 		cr := DecodeCoinRecord(pcr)
 		return cr
@@ -394,7 +461,7 @@ func (coinDB *CoinDatabase) GetCoin(cl CoinLocator) *Coin {
 	}
 }
 
-//GetBalance returns the current balance of the publicKey
+// GetBalance returns the current balance of the publicKey
 func (coinDB *CoinDatabase) GetBalance(publicKey string) uint32 {
 	coinDB.FlushMainCache()
 	balance := uint32(0)
@@ -426,7 +493,7 @@ func contains(s []uint32, e uint32) bool {
 	return false
 }
 
-//indexOf returns the index of element e in int slice s, -1 if the element does not exist.
+// indexOf returns the index of element e in int slice s, -1 if the element does not exist.
 func indexOf(s []uint32, e uint32) int {
 	for i, a := range s {
 		if a == e {
@@ -4,9 +4,14 @@ import (
 	"Coin/pkg/blockchain/blockinfodatabase"
 	"Coin/pkg/blockchain/chainwriter"
 	"Coin/pkg/blockchain/coindatabase"
+	"Coin/pkg/utils"
 )
 
 // Config is the BlockChain's configuration options.
+// MedianTimeSpan is the number of blocks whose timestamps
+// are used to calculate the median time past.
+// POWLimit is the easiest difficulty target that a block
+// header is allowed to have.
 type Config struct {
 	GenesisPublicKey  string
 	InitialSubsidy    uint32
@@ -14,6 +19,8 @@ type Config struct {
 	BlockInfoDBPath   string
 	ChainWriterDBPath string
 	CoinDBPath        string
+	MedianTimeSpan    uint32
+	POWLimit          string
 }
 
 // GENPK is the public key that was used
@@ -35,5 +42,7 @@ func DefaultConfig() *Config {
 		BlockInfoDBPath:   blockinfodatabase.DefaultConfig().DatabasePath,
 		ChainWriterDBPath: chainwriter.DefaultConfig().DataDirectory,
 		CoinDBPath:        coindatabase.DefaultConfig().DatabasePath,
+		MedianTimeSpan:    11,
+		POWLimit:          string(utils.CalcPOWD(-1)),
 	}
 }
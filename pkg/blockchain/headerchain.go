@@ -0,0 +1,163 @@
+package blockchain
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain/blockinfodatabase"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"sync"
+)
+
+// HeaderChain is a header-only view of the blockchain, used by
+// light clients. It validates and stores headers (as BlockRecords
+// without any file information) in a BlockInfoDatabase, but never
+// stores blocks or coins.
+// Length is the length of the best header chain.
+// LastHash is the hash of the last header of the best chain.
+// LastHeader is the last header of the best chain.
+// BlockInfoDB stores a BlockRecord for every valid header.
+// genesisHash is the hash of the genesis header.
+// powLimit is the easiest difficulty target a header may have.
+// medianTimeSpan is the number of headers used for the median time past.
+type HeaderChain struct {
+	Length     uint32
+	LastHash   string
+	LastHeader *block.Header
+
+	BlockInfoDB *blockinfodatabase.BlockInfoDatabase
+
+	genesisHash    string
+	powLimit       string
+	medianTimeSpan uint32
+	mutex          sync.Mutex
+}
+
+// NewHeaderChain returns a HeaderChain given a Config. Only the
+// Config's BlockInfoDBPath is used for storage.
+func NewHeaderChain(config *Config) *HeaderChain {
+	genesis := GenesisBlock(config)
+	hash := genesis.Hash()
+	blockInfoDBConfig := blockinfodatabase.DefaultConfig()
+	blockInfoDBConfig.DatabasePath = config.BlockInfoDBPath
+	hc := &HeaderChain{
+		Length:         1,
+		LastHash:       hash,
+		LastHeader:     genesis.Header,
+		BlockInfoDB:    blockinfodatabase.New(blockInfoDBConfig),
+		genesisHash:    hash,
+		powLimit:       config.POWLimit,
+		medianTimeSpan: config.MedianTimeSpan,
+	}
+	hc.BlockInfoDB.StoreBlockRecord(hash, &blockinfodatabase.BlockRecord{
+		Header:               genesis.Header,
+		Height:               1,
+		NumberOfTransactions: uint32(len(genesis.Transactions)),
+	})
+	return hc
+}
+
+// HandleHeader validates a header and stores it. A header is valid if:
+// (1) its previous header is already known (linkage),
+// (2) its difficulty target is no easier than the POWLimit,
+// (3) its hash is below its difficulty target (proof of work),
+// (4) its timestamp is above the median time past of its ancestors.
+// If the header makes a longer chain than the current best one,
+// it becomes the new tip.
+// Returns:
+// bool true if the header became the new tip
+// error if the header was invalid (with a reject code)
+func (hc *HeaderChain) HandleHeader(h *block.Header) (bool, error) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	hash := h.Hash()
+	if hc.BlockInfoDB.HasBlockRecord(hash) {
+		return false, nil
+	}
+	if !hc.BlockInfoDB.HasBlockRecord(h.PreviousHash) {
+		return false, reject.New(pro.RejectCode_REJECT_ORPHAN, hash, "[HeaderChain.HandleHeader] previous header is unknown")
+	}
+	if err := h.CheckProofOfWork(hc.powLimit); err != nil {
+		return false, reject.Errorf(pro.RejectCode_REJECT_BAD_POW, hash, "[HeaderChain.HandleHeader] %v", err)
+	}
+	if h.Timestamp <= medianTimePast(hc.BlockInfoDB, h.PreviousHash, hc.medianTimeSpan) {
+		return false, reject.New(pro.RejectCode_REJECT_BAD_TIMESTAMP, hash, "[HeaderChain.HandleHeader] timestamp is not above the median time past")
+	}
+	height := hc.BlockInfoDB.GetBlockRecord(h.PreviousHash).Height + 1
+	hc.BlockInfoDB.StoreBlockRecord(hash, &blockinfodatabase.BlockRecord{Header: h, Height: height})
+	if height <= hc.Length {
+		return false, nil
+	}
+	hc.Length = height
+	hc.LastHash = hash
+	hc.LastHeader = h
+	return true, nil
+}
+
+// GetHeader returns the header with the given hash, or nil
+// if it is unknown.
+func (hc *HeaderChain) GetHeader(hash string) *block.Header {
+	if !hc.BlockInfoDB.HasBlockRecord(hash) {
+		return nil
+	}
+	return hc.BlockInfoDB.GetBlockRecord(hash).Header
+}
+
+// Confirmations returns how many headers of the best chain are
+// at or above the header with the given hash. It returns 0 if
+// the header is unknown or not on the best chain.
+func (hc *HeaderChain) Confirmations(hash string) uint32 {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	if !hc.BlockInfoDB.HasBlockRecord(hash) {
+		return 0
+	}
+	height := hc.BlockInfoDB.GetBlockRecord(hash).Height
+	if height > hc.Length {
+		return 0
+	}
+	nextHash := hc.LastHash
+	for i := hc.Length; i > height; i-- {
+		nextHash = hc.BlockInfoDB.GetBlockRecord(nextHash).Header.PreviousHash
+	}
+	if nextHash != hash {
+		return 0
+	}
+	return hc.Length - height + 1
+}
+
+// Locator returns hashes of headers on the best chain, newest
+// first, that a full node can use to find where our chain and its
+// chain split. The first ten hashes are consecutive, after that the
+// step doubles each time. The genesis hash is always last.
+func (hc *HeaderChain) Locator() []string {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	return locator(hc.BlockInfoDB, hc.LastHash, hc.genesisHash)
+}
+
+// Close is used to actually shut down the db (for testing purposes)
+func (hc *HeaderChain) Close() {
+	hc.BlockInfoDB.Close()
+}
+
+// locator returns the hashes of BlockRecords in blockInfoDB on the
+// chain ending at tip, newest first. The first ten hashes are
+// consecutive, after that the step doubles each time. The genesis
+// hash is always last.
+func locator(blockInfoDB *blockinfodatabase.BlockInfoDatabase, tip string, genesisHash string) []string {
+	var hashes []string
+	step := 1
+	nextHash := tip
+	for {
+		hashes = append(hashes, nextHash)
+		if len(hashes) >= 10 {
+			step *= 2
+		}
+		for i := 0; i < step && nextHash != genesisHash; i++ {
+			nextHash = blockInfoDB.GetBlockRecord(nextHash).Header.PreviousHash
+		}
+		if nextHash == hashes[len(hashes)-1] {
+			return hashes
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package pkg
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/block"
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"Coin/pkg/validation"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxDownloadFailures is how many block requests in a row
+// a peer may fail before we stop downloading from it.
+const maxDownloadFailures = 3
+
+// maxSyncHeaders is the most headers downloaded from a peer
+// in one sync.
+const maxSyncHeaders = 10 * MaxHeadersPerResponse
+
+// headerRun is a run of validated headers that a peer sent
+// during initial sync.
+// addr is the peer that sent the headers.
+// headers are the headers, oldest first.
+// height is the height of the last header.
+type headerRun struct {
+	addr    *address.Address
+	headers []*block.Header
+	height  uint32
+}
+
+// blockResult is the outcome of a single block request.
+// index is the position of the block in the sync.
+// block is the block, or nil if the request failed.
+// addr is the peer the block was requested from.
+// quit is whether the peer's worker has stopped.
+type blockResult struct {
+	index int
+	block *block.Block
+	addr  *address.Address
+	err   error
+	quit  bool
+}
+
+// SyncBlocks does a headers-first sync of the node's blockchain.
+// It first downloads and validates the header chain of every
+// full peer, and picks the one that reaches the greatest height.
+// Then it downloads the blocks of that chain in parallel from
+// every peer that sent the same chain, and connects them in order.
+func (n *Node) SyncBlocks() error {
+	peers := n.fullPeers()
+	if len(peers) == 0 {
+		return errors.New("no full peers to sync blocks from")
+	}
+	utils.Debug.Printf("%v syncing blocks from %v peers at height %v",
+		utils.FmtAddr(n.Address), len(peers), n.bestHeight())
+	runs := make([]*headerRun, len(peers))
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func(i int, p *peer.Peer) {
+			defer wg.Done()
+			run, err := n.downloadHeaders(p.Addr)
+			if err != nil {
+				utils.Debug.Printf("%v could not sync headers from %v: %v",
+					utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr), err)
+				return
+			}
+			if run != nil {
+				p.UpdateBestHeight(run.height)
+			}
+			runs[i] = run
+		}(i, p)
+	}
+	wg.Wait()
+	var best *headerRun
+	for _, run := range runs {
+		if run != nil && (best == nil || run.height > best.height) {
+			best = run
+		}
+	}
+	if best == nil || best.height <= n.bestHeight() {
+		return nil
+	}
+	tip := best.headers[len(best.headers)-1].Hash()
+	var sources []*address.Address
+	for _, run := range runs {
+		if run != nil && run.headers[len(run.headers)-1].Hash() == tip {
+			sources = append(sources, run.addr)
+		}
+	}
+	hashes := make([]string, len(best.headers))
+	for i, h := range best.headers {
+		hashes[i] = h.Hash()
+	}
+	return n.downloadBlocks(hashes, best.height-uint32(len(hashes))+1, sources)
+}
+
+// downloadHeaders asks a peer for the headers after our active
+// chain until it has nothing more to send (or has sent
+// maxSyncHeaders), and validates each response as it arrives.
+// Whatever is left after maxSyncHeaders is synced next time.
+// Returns:
+// *headerRun the headers, or nil if the peer had none
+// error if the peer did not respond or sent invalid headers
+func (n *Node) downloadHeaders(addr *address.Address) (*headerRun, error) {
+	var headers []*block.Header
+	var height uint32
+	n.chainMutex.RLock()
+	locator := n.BlockChain.Locator()
+	n.chainMutex.RUnlock()
+	for len(headers) < maxSyncHeaders {
+		res, err := addr.GetHeadersRPC(&pro.GetHeadersRequest{LocatorHashes: locator})
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Headers) == 0 {
+			break
+		}
+		batch := make([]*block.Header, 0, len(res.Headers))
+		for _, ph := range res.Headers {
+			h := block.DecodeHeader(ph)
+			if !n.CheckHeaderTime(h) {
+				n.misbehaving(addr.Addr, peer.InvalidHeaders)
+				return nil, errors.New("header is too far in the future")
+			}
+			batch = append(batch, h)
+		}
+		if height, err = n.BlockChain.CheckHeaders(headers, batch); err != nil {
+			n.misbehaving(addr.Addr, peer.InvalidHeaders)
+			return nil, err
+		}
+		headers = append(headers, batch...)
+		if len(res.Headers) < MaxHeadersPerResponse {
+			break
+		}
+		locator = append([]string{headers[len(headers)-1].Hash()}, locator...)
+	}
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	return &headerRun{addr: addr, headers: headers, height: height}, nil
+}
+
+// downloadBlocks downloads the blocks with the given hashes in
+// parallel from the given peers and connects them in order.
+// At most SyncWindow blocks past the next block to connect are
+// requested at once. A request that fails or times out is made
+// again (possibly to another peer), and a peer that fails too
+// many requests in a row is dropped.
+// Inputs:
+// hashes []string the hashes of the blocks, oldest first
+// startHeight uint32 the height of the first block
+// peers []*address.Address the peers to download from
+// Returns:
+// error if the blocks could not be downloaded or were invalid
+func (n *Node) downloadBlocks(hashes []string, startHeight uint32, peers []*address.Address) error {
+	window := n.Config.SyncWindow
+	if window < 1 {
+		window = 1
+	}
+	// requests never blocks: at most window blocks are
+	// outstanding, and each is in the channel at most once.
+	requests := make(chan int, window)
+	results := make(chan *blockResult)
+	done := make(chan struct{})
+	defer close(done)
+	for _, addr := range peers {
+		go n.blockWorker(addr, hashes, requests, results, done)
+	}
+	workers := len(peers)
+	// results that arrived before the blocks before them
+	blocks := make(map[int]*blockResult)
+	next, queued := 0, 0
+	for next < len(hashes) {
+		for ; queued < len(hashes) && queued < next+window; queued++ {
+			requests <- queued
+		}
+		r := <-results
+		if r.quit {
+			workers--
+		}
+		if r.err != nil {
+			utils.Debug.Printf("%v failed to download block %v from %v: %v",
+				utils.FmtAddr(n.Address), hashes[r.index][:8], utils.FmtAddr(r.addr.Addr), r.err)
+			if workers == 0 {
+				return fmt.Errorf("no peers left to download block %v from", hashes[r.index])
+			}
+			requests <- r.index
+			continue
+		}
+		blocks[r.index] = r
+		for r, ok := blocks[next]; ok; r, ok = blocks[next] {
+			delete(blocks, next)
+			if err := n.connectBlock(r.block, startHeight+uint32(next)); err != nil {
+				n.misbehaving(r.addr.Addr, peer.InvalidBlock)
+				return err
+			}
+			next++
+		}
+	}
+	return nil
+}
+
+// connectBlock validates a block downloaded during initial sync
+// and adds it to the blockchain (which checks its coins). A block
+// that extends the main chain is checked against its coins like
+// any other block; one on another branch (which only happens when
+// the sync switches to a longer chain) can only be checked against
+// them once the blockchain switches to its branch.
+func (n *Node) connectBlock(b *block.Block, height uint32) error {
+	n.chainMutex.Lock()
+	c := n.validationContext(b.Header.PreviousHash)
+	if b.Header.PreviousHash != n.BlockChain.LastHash {
+		c.Coins = nil
+	}
+	if err := validation.CheckBlock(c, b, validation.BlockRules); err != nil {
+		n.chainMutex.Unlock()
+		return fmt.Errorf("%v is not valid: %w", b.NameTag(), err)
+	}
+	extends := height > n.BlockChain.Length
+	n.markSeen(pro.InventoryType_INVENTORY_BLOCK, b.Hash())
+	n.updateMempool(n.BlockChain.HandleBlock(b))
+	connected := !extends || n.BlockChain.LastHash == b.Hash()
+	n.chainMutex.Unlock()
+	if !connected {
+		return fmt.Errorf("%v is not valid", b.NameTag())
+	}
+	n.reportIBDProgress()
+	n.UpdateIBD()
+	return nil
+}
+
+// blockWorker downloads blocks from a single peer. It takes
+// indexes of blocks to download from requests and sends the
+// outcome to results, until done is closed or the peer fails
+// maxDownloadFailures requests in a row.
+func (n *Node) blockWorker(addr *address.Address, hashes []string, requests <-chan int, results chan<- *blockResult, done <-chan struct{}) {
+	failures := 0
+	for {
+		select {
+		case i := <-requests:
+			b, err := n.fetchBlock(addr, hashes[i])
+			if err != nil {
+				failures++
+			} else {
+				failures = 0
+			}
+			r := &blockResult{index: i, block: b, addr: addr, err: err, quit: failures >= maxDownloadFailures}
+			select {
+			case results <- r:
+			case <-done:
+				return
+			}
+			if r.quit {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// fetchBlock requests a single block from a peer, and checks
+// that the peer sent the block that was asked for, with the
+// transactions that its header commits to.
+func (n *Node) fetchBlock(addr *address.Address, hash string) (*block.Block, error) {
+	replies := make(chan *pro.GetDataResponse, 1)
+	go func() {
+		res, err := addr.GetDataRPC(&pro.GetDataRequest{BlockHash: hash})
+		if err != nil {
+			res = nil
+		}
+		replies <- res
+	}()
+	select {
+	case res := <-replies:
+		if res == nil || res.Block == nil {
+			return nil, errors.New("peer did not send the block")
+		}
+		b := block.DecodeBlock(res.Block)
+		if b.Hash() != hash {
+			n.misbehaving(addr.Addr, peer.UnexpectedResponse)
+			return nil, errors.New("peer sent the wrong block")
+		}
+		if b.Header.MerkleRoot != block.CalculateMerkleRoot(b.Transactions) {
+			n.misbehaving(addr.Addr, peer.InvalidBlock)
+			return nil, errors.New("block's transactions do not match its merkle root")
+		}
+		return b, nil
+	case <-time.After(n.Config.BlockDownloadTimeout):
+		return nil, errors.New("timed out")
+	}
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entryOverhead estimates the bytes an entry takes besides its
+// key: the list element, the map entry and the entry itself.
+const entryOverhead = 128
+
+// Recent remembers recently added keys (such as the hashes of
+// objects a node has seen). It forgets the key added longest
+// ago once it holds capacity keys, and keys older than ttl.
+// Adding a key again renews it.
+// It is safe for concurrent use.
+// capacity is the most keys it holds.
+// ttl is how long a key is remembered (0 means forever).
+// entries maps keys to their elements in order.
+// order holds the entries, most recently added first.
+// bytes is the estimated memory use of the entries.
+// hits, misses, evicted and expired count what happened to
+// lookups and entries (see Stats).
+type Recent struct {
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List
+	bytes    int
+	hits     uint64
+	misses   uint64
+	evicted  uint64
+	expired  uint64
+	mutex    sync.Mutex
+}
+
+// entry is a key and when it was (last) added.
+type entry struct {
+	key   string
+	added time.Time
+}
+
+// Stats is a snapshot of how a Recent has been used.
+// Entries is how many keys it holds.
+// Hits and Misses count the lookups of keys it held and did not.
+// HitRate is Hits over all lookups (0 before any lookup).
+// Evicted counts the keys dropped to stay within capacity,
+// and Expired those dropped for being older than the ttl.
+// MemoryBytes is an estimate of the memory the keys use.
+type Stats struct {
+	Entries     int
+	Hits        uint64
+	Misses      uint64
+	HitRate     float64
+	Evicted     uint64
+	Expired     uint64
+	MemoryBytes int
+}
+
+// NewRecent returns an empty Recent that holds up to capacity
+// keys for up to ttl.
+func NewRecent(capacity int, ttl time.Duration) *Recent {
+	return &Recent{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Add remembers a key (a lookup, see Stats).
+// Returns:
+// bool true if the key was not remembered yet
+func (r *Recent) Add(key string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	r.expire(now)
+	if el, ok := r.entries[key]; ok {
+		r.hits++
+		el.Value.(*entry).added = now
+		r.order.MoveToFront(el)
+		return false
+	}
+	r.misses++
+	r.entries[key] = r.order.PushFront(&entry{key: key, added: now})
+	r.bytes += len(key) + entryOverhead
+	for r.order.Len() > r.capacity {
+		r.remove(r.order.Back())
+		r.evicted++
+	}
+	return true
+}
+
+// Contains returns whether a key is remembered (a lookup, see Stats).
+func (r *Recent) Contains(key string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.expire(time.Now())
+	if _, ok := r.entries[key]; ok {
+		r.hits++
+		return true
+	}
+	r.misses++
+	return false
+}
+
+// Remove forgets a key, so that it can be added again.
+func (r *Recent) Remove(key string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if el, ok := r.entries[key]; ok {
+		r.remove(el)
+	}
+}
+
+// Len returns how many keys are remembered.
+func (r *Recent) Len() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.expire(time.Now())
+	return r.order.Len()
+}
+
+// Stats returns how the Recent has been used so far.
+func (r *Recent) Stats() Stats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.expire(time.Now())
+	s := Stats{
+		Entries:     r.order.Len(),
+		Hits:        r.hits,
+		Misses:      r.misses,
+		Evicted:     r.evicted,
+		Expired:     r.expired,
+		MemoryBytes: r.bytes,
+	}
+	if lookups := r.hits + r.misses; lookups > 0 {
+		s.HitRate = float64(r.hits) / float64(lookups)
+	}
+	return s
+}
+
+// expire forgets the keys added more than ttl ago, which are
+// at the back of the order.
+func (r *Recent) expire(now time.Time) {
+	if r.ttl <= 0 {
+		return
+	}
+	for el := r.order.Back(); el != nil && now.Sub(el.Value.(*entry).added) > r.ttl; el = r.order.Back() {
+		r.remove(el)
+		r.expired++
+	}
+}
+
+// remove forgets an entry.
+func (r *Recent) remove(el *list.Element) {
+	e := r.order.Remove(el).(*entry)
+	delete(r.entries, e.key)
+	r.bytes -= len(e.key) + entryOverhead
+}
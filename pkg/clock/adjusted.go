@@ -0,0 +1,104 @@
+package clock
+
+import (
+	"Coin/pkg/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AdjustedClock is a network-adjusted Clock. Every peer
+// tells us what time it thinks it is during the version
+// handshake. The clock keeps the offset between each peer's
+// time and our own and shifts the base clock by the median
+// of those offsets (our own zero offset included), so a
+// single peer with a broken clock can't move us.
+// base is the underlying clock being adjusted.
+// offsets maps a peer's address to the offset it reported.
+// order remembers the insertion order of the samples so that
+// the oldest can be dropped once maxSamples is hit.
+// minSamples is how many peer samples are needed before
+// any adjustment is made.
+// maxAdjustment is the largest offset the clock will ever
+// apply. If the median is larger, the network is likely
+// wrong (or we are) and the clock falls back to base.
+type AdjustedClock struct {
+	base          Clock
+	offsets       map[string]time.Duration
+	order         []string
+	maxSamples    int
+	minSamples    int
+	maxAdjustment time.Duration
+	offset        time.Duration
+	mutex         sync.Mutex
+}
+
+// NewAdjustedClock returns an AdjustedClock on top of base.
+func NewAdjustedClock(base Clock, minSamples int, maxSamples int, maxAdjustment time.Duration) *AdjustedClock {
+	if base == nil {
+		base = SystemClock{}
+	}
+	return &AdjustedClock{
+		base:          base,
+		offsets:       make(map[string]time.Duration),
+		maxSamples:    maxSamples,
+		minSamples:    minSamples,
+		maxAdjustment: maxAdjustment,
+	}
+}
+
+// Now returns the base clock's time shifted by the
+// current network offset.
+func (c *AdjustedClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.base.Now().Add(c.offset)
+}
+
+// Offset returns the offset currently applied to the base clock.
+func (c *AdjustedClock) Offset() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.offset
+}
+
+// AddSample records the time a peer reported. Only the
+// latest sample from each source is kept.
+// Inputs:
+// source string the address of the peer that reported the time
+// remote time.Time the time the peer reported
+func (c *AdjustedClock) AddSample(source string, remote time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.offsets[source]; !ok {
+		if len(c.order) >= c.maxSamples {
+			delete(c.offsets, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, source)
+	}
+	c.offsets[source] = remote.Sub(c.base.Now())
+	c.updateOffset()
+}
+
+// updateOffset recalculates the offset from the samples.
+// The caller must hold the mutex.
+func (c *AdjustedClock) updateOffset() {
+	if len(c.offsets) < c.minSamples {
+		c.offset = 0
+		return
+	}
+	offsets := []time.Duration{0}
+	for _, o := range c.offsets {
+		offsets = append(offsets, o)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	median := offsets[len(offsets)/2]
+	if median > c.maxAdjustment || median < -c.maxAdjustment {
+		utils.Debug.Printf("[clock.AdjustedClock] network time offset %v is larger than %v, ignoring it",
+			median, c.maxAdjustment)
+		c.offset = 0
+		return
+	}
+	c.offset = median
+}
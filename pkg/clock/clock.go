@@ -0,0 +1,55 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is anything that can tell the time. The node,
+// miner and validation code never call time.Now directly
+// for consensus decisions, they ask a Clock, which means
+// tests can swap in a ManualClock and drive time themselves.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is a Clock backed by the local system time.
+type SystemClock struct{}
+
+// Now returns the local system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock is a Clock that only moves when it is told to.
+// It is meant for tests.
+type ManualClock struct {
+	t     time.Time
+	mutex sync.Mutex
+}
+
+// NewManualClock returns a ManualClock set to t.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{t: t}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.t
+}
+
+// Set sets the clock's current time.
+func (c *ManualClock) Set(t time.Time) {
+	c.mutex.Lock()
+	c.t = t
+	c.mutex.Unlock()
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	c.t = c.t.Add(d)
+	c.mutex.Unlock()
+}
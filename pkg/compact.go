@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"errors"
+	"sync/atomic"
+)
+
+// compactStats counts how compact blocks from peers were handled.
+// rebuilt is how many were rebuilt from transactions we had.
+// requested is how many transactions had to be requested while
+// rebuilding them.
+// fellBack is how many could not be rebuilt, so the full block
+// was requested instead.
+type compactStats struct {
+	rebuilt   uint64
+	requested uint64
+	fellBack  uint64
+}
+
+// CompactBlockStats is a snapshot of how compact blocks from
+// peers were handled.
+// Rebuilt is how many were rebuilt, possibly after requesting
+// missing transactions.
+// RequestedTransactions is how many missing transactions were
+// requested from peers.
+// FellBack is how many could not be rebuilt, so the full block
+// was requested instead.
+type CompactBlockStats struct {
+	Rebuilt               uint64
+	RequestedTransactions uint64
+	FellBack              uint64
+}
+
+// CompactBlockStats returns how compact blocks from peers
+// were handled so far.
+func (n *Node) CompactBlockStats() *CompactBlockStats {
+	return &CompactBlockStats{
+		Rebuilt:               atomic.LoadUint64(&n.compact.rebuilt),
+		RequestedTransactions: atomic.LoadUint64(&n.compact.requested),
+		FellBack:              atomic.LoadUint64(&n.compact.fellBack),
+	}
+}
+
+// blockRequestType returns the inventory type a node asks for
+// when it wants an announced block.
+func (n *Node) blockRequestType() pro.InventoryType {
+	if n.Config.CompactBlocks {
+		return pro.InventoryType_INVENTORY_COMPACT_BLOCK
+	}
+	return pro.InventoryType_INVENTORY_BLOCK
+}
+
+// handleCompactBlock handles a compact block sent by the peer
+// with address from. Light clients only need its header. Full
+// nodes rebuild the block from the transactions in their
+// mempool, request any missing transactions from the peer, and
+// request the full block if that fails.
+func (n *Node) handleCompactBlock(cb *block.CompactBlock, from string) error {
+	hash := cb.Hash()
+	if n.Config.LightClient {
+		return n.handleBlock(&block.Block{Header: cb.Header}, from)
+	}
+	if n.SeenBlocks.Contains(hash) {
+		return nil
+	}
+	if !n.BlockChain.BlockInfoDB.HasBlockRecord(cb.Header.PreviousHash) {
+		// there is no point rebuilding a block we can't add yet
+		return n.handleBlock(&block.Block{Header: cb.Header}, from)
+	}
+	b, err := n.rebuildCompactBlock(cb, from)
+	if err != nil {
+		utils.Debug.Printf("%v could not rebuild compact block %v from %v: %v",
+			utils.FmtAddr(n.Address), hash[:8], utils.FmtAddr(from), err)
+		atomic.AddUint64(&n.compact.fellBack, 1)
+		return n.fetchFullBlock(hash, from)
+	}
+	atomic.AddUint64(&n.compact.rebuilt, 1)
+	return n.handleBlock(b, from)
+}
+
+// rebuildCompactBlock rebuilds a compact block from the
+// transactions in the node's mempool (or, for nodes without
+// one, the transactions it recently relayed), and requests the
+// missing ones from the peer with address from.
+func (n *Node) rebuildCompactBlock(cb *block.CompactBlock, from string) (*block.Block, error) {
+	var pool []*block.Transaction
+	if n.Mempool != nil {
+		pool = n.Mempool.Transactions()
+	} else {
+		n.inv.mutex.Lock()
+		pool = make([]*block.Transaction, 0, len(n.inv.relay))
+		for _, t := range n.inv.relay {
+			pool = append(pool, t)
+		}
+		n.inv.mutex.Unlock()
+	}
+	pb, err := cb.Rebuild(pool)
+	if err != nil {
+		return nil, err
+	}
+	if missing := pb.Missing(); len(missing) > 0 {
+		p := n.PeerDb.Get(from)
+		if p == nil {
+			return nil, errors.New("sender is no longer a peer")
+		}
+		atomic.AddUint64(&n.compact.requested, uint64(len(missing)))
+		res, err := p.Addr.GetBlockTransactionsRPC(&pro.GetBlockTransactionsRequest{
+			BlockHash: cb.Hash(),
+			Indexes:   missing,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var txs []*block.Transaction
+		for _, pt := range res.Transactions {
+			txs = append(txs, block.DecodeTransaction(pt))
+		}
+		if err := pb.Fill(txs); err != nil {
+			n.misbehaving(from, peer.UnexpectedResponse)
+			return nil, err
+		}
+	}
+	return pb.Block()
+}
+
+// fetchFullBlock requests a block from the peer with address
+// from, and handles it.
+func (n *Node) fetchFullBlock(hash string, from string) error {
+	p := n.PeerDb.Get(from)
+	if p == nil {
+		return errors.New("sender is no longer a peer")
+	}
+	res, err := p.Addr.GetObjectsRPC(&pro.GetObjectsRequest{Items: []*pro.InventoryItem{
+		{Type: pro.InventoryType_INVENTORY_BLOCK, Hash: hash},
+	}})
+	if err != nil {
+		return err
+	}
+	for _, pb := range res.Blocks {
+		if b := block.DecodeBlock(pb); b.Hash() == hash {
+			return n.handleBlock(b, from)
+		}
+	}
+	return errors.New("peer did not send the block")
+}
@@ -1,13 +1,18 @@
 package pkg
 
 import (
+	"Coin/pkg/address"
 	"Coin/pkg/blockchain"
+	"Coin/pkg/clock"
 	"Coin/pkg/id"
 	"Coin/pkg/miner"
 	"Coin/pkg/wallet"
 	"time"
 )
 
+// DefaultUserAgent is the user agent nodes advertise by default
+const DefaultUserAgent = "/coin:0.1.0/"
+
 // Config is the configuration for the node.
 // IdConf is the configuration for the id,
 // MinerConfig is the configuration for the miner,
@@ -15,12 +20,91 @@ import (
 // ChainConfig is the configuration for the blockchain,
 // Version is the version that the node is (used for
 // software updates),
+// MinVersion is the oldest protocol version of peers the
+// node talks to,
+// UserAgent is the software the node says it runs,
 // PeerLimit is the maximum amount of peers the node
 // is allowed to have,
 // AddressLimit is the maximum amount of addresses the
 // node is allowed to keep track of.
 // Port is the port that the node should run on,
 // MaxBlockSize is the maximum allowed block size,
+// MaxStandardTransactionSize is the largest transaction the
+// node relays (see validation.StandardSize),
+// MinRelayFee is the smallest fee of a transaction the node
+// relays (see validation.MinRelayFee),
+// Clock is the local clock that the network-adjusted
+// clock is built on (nil means the system clock),
+// MaxFutureBlockTime is how far past network-adjusted
+// time a block's timestamp may be,
+// MinTimeSamples is how many peer clocks must be heard
+// from before the network-adjusted clock starts adjusting,
+// MaxTimeSamples is the most peer clocks that are kept,
+// MaxClockAdjustment is the largest adjustment the
+// network-adjusted clock will make,
+// LightClient is whether the node is a light client that
+// only syncs headers and verifies payments with merkle proofs,
+// SyncWindow is the most blocks that may be requested (or
+// waiting to be connected) at once during initial sync,
+// BlockDownloadTimeout is how long a peer has to send a
+// requested block before it is requested again,
+// SyncInterval is how often the node checks whether a peer
+// has advertised a better chain than its own,
+// MaxTipAge is how old the node's tip may get before the
+// node considers itself to be in initial block download
+// (when a peer has a better chain),
+// IBDHeightLag is how far behind the best peer-advertised
+// height the node may be before it is in initial block
+// download, no matter how old its tip is,
+// CompactBlocks is whether announced blocks are requested
+// as compact blocks, which are rebuilt from transactions the
+// node already has,
+// BanThreshold is the misbehavior score at which a peer is
+// disconnected and banned,
+// BanDuration is how long misbehaving peers are banned for,
+// BanListPath is where the ban list is stored,
+// AddressDBPath is where known addresses are stored (if it
+// is empty, they are only kept in memory),
+// PeerDBPath is where peers are stored, so that the node
+// can reconnect to them after a restart (if it is empty,
+// they are only kept in memory),
+// AddressInterval is how often the node re-advertises its
+// address to its peers (see runDiscovery),
+// MaxOutbound is how many outbound peers the connection
+// manager keeps (see runConnectionManager),
+// MaxInbound is the most inbound peers the node accepts,
+// Seeds are addresses to connect to when the node knows
+// no other addresses,
+// ConnectOnly are the only addresses the connection manager
+// connects to, if it is set,
+// ConnectionInterval is how often the connection manager
+// checks that the node has enough outbound peers,
+// PingInterval is how often the node pings its peers,
+// StaleTimeout is how long a peer may go unheard from
+// before it is evicted,
+// TLS is whether the node only talks to other nodes over
+// TLS, with certificates for the key of its id (so that
+// peers prove their identity),
+// GlobalRequestRate and GlobalRequestBurst limit how many
+// requests per second the node handles from everyone together,
+// PeerRequestRate and PeerRequestBurst limit how many requests
+// per second it handles from each peer (see admit),
+// MaxExpensiveRequests is how many requests that read blocks
+// the node handles at once,
+// MaxMessageSize is the largest message the node's server
+// receives or sends,
+// SeenTransactionsSize and SeenBlocksSize are how many
+// transaction and block hashes the node remembers seeing,
+// SeenTTL is how long it remembers them,
+// MempoolSize is the most bytes of unconfirmed transactions
+// the node keeps (see mempool.Mempool),
+// MempoolExpiry is how long an unconfirmed transaction is kept,
+// also across restarts,
+// MempoolPath is where the mempool is stored when the node is
+// killed, so that it is loaded again on the next start (if it
+// is empty, the mempool is only kept in memory),
+// MaxReplacements is the most transactions (with descendants)
+// a replacement may evict from the mempool.
 type Config struct {
 	IdConfig     *id.Config
 	MinerConfig  *miner.Config
@@ -31,12 +115,69 @@ type Config struct {
 	CustomID    id.ID
 
 	Version        int
+	MinVersion     int
+	UserAgent      string
 	PeerLimit      int
 	AddressLimit   int
 	Port           int
 	VersionTimeout time.Duration
 
-	MaxBlockSize uint32
+	MaxBlockSize               uint32
+	MaxStandardTransactionSize uint32
+	MinRelayFee                uint32
+
+	Clock              clock.Clock
+	MaxFutureBlockTime time.Duration
+	MinTimeSamples     int
+	MaxTimeSamples     int
+	MaxClockAdjustment time.Duration
+
+	LightClient bool
+
+	SyncWindow           int
+	BlockDownloadTimeout time.Duration
+	SyncInterval         time.Duration
+
+	MaxTipAge    time.Duration
+	IBDHeightLag uint32
+
+	CompactBlocks bool
+
+	BanThreshold uint32
+	BanDuration  time.Duration
+	BanListPath  string
+
+	AddressDBPath string
+	PeerDBPath    string
+
+	AddressInterval time.Duration
+
+	MaxOutbound        int
+	MaxInbound         int
+	Seeds              []string
+	ConnectOnly        []string
+	ConnectionInterval time.Duration
+
+	PingInterval time.Duration
+	StaleTimeout time.Duration
+
+	TLS bool
+
+	GlobalRequestRate    float64
+	GlobalRequestBurst   float64
+	PeerRequestRate      float64
+	PeerRequestBurst     float64
+	MaxExpensiveRequests int
+	MaxMessageSize       int
+
+	SeenTransactionsSize int
+	SeenBlocksSize       int
+	SeenTTL              time.Duration
+
+	MempoolSize     uint32
+	MempoolExpiry   time.Duration
+	MempoolPath     string
+	MaxReplacements int
 }
 
 // DefaultConfig creates a Config object that
@@ -55,11 +196,62 @@ func DefaultConfig(port int) *Config {
 		WalletConfig:   wallet.DefaultConfig(),
 		ChainConfig:    blockchain.DefaultConfig(),
 		Version:        0,
+		MinVersion:     0,
+		UserAgent:      DefaultUserAgent,
 		PeerLimit:      20,
 		AddressLimit:   1000,
 		Port:           port,
 		VersionTimeout: time.Second * 2,
 		MaxBlockSize:   10000000,
+
+		MaxStandardTransactionSize: 100000,
+		MinRelayFee:                1,
+
+		Clock:              clock.SystemClock{},
+		MaxFutureBlockTime: time.Hour * 2,
+		MinTimeSamples:     5,
+		MaxTimeSamples:     200,
+		MaxClockAdjustment: time.Minute * 70,
+
+		SyncWindow:           16,
+		BlockDownloadTimeout: time.Second * 5,
+		SyncInterval:         time.Second * 10,
+
+		MaxTipAge:    time.Hour * 24,
+		IBDHeightLag: 144,
+
+		CompactBlocks: true,
+
+		BanThreshold: 100,
+		BanDuration:  time.Hour * 24,
+		BanListPath:  "banlist",
+
+		AddressDBPath: "addressdata",
+		PeerDBPath:    "peerdata",
+
+		AddressInterval: time.Minute * 10,
+
+		MaxOutbound:        8,
+		MaxInbound:         12,
+		ConnectionInterval: time.Second * 30,
+		PingInterval:       time.Minute * 2,
+		StaleTimeout:       time.Minute * 20,
+
+		GlobalRequestRate:    10000,
+		GlobalRequestBurst:   20000,
+		PeerRequestRate:      1000,
+		PeerRequestBurst:     2000,
+		MaxExpensiveRequests: 8,
+		MaxMessageSize:       address.MaxMessageSize,
+
+		SeenTransactionsSize: 50000,
+		SeenBlocksSize:       5000,
+		SeenTTL:              time.Hour * 24,
+
+		MempoolSize:     5000000,
+		MempoolExpiry:   time.Hour * 24 * 14,
+		MempoolPath:     "mempool.dat",
+		MaxReplacements: 100,
 	}
 	return c
 }
@@ -71,11 +263,62 @@ func TestingConfig(port int) *Config {
 		WalletConfig:   wallet.DefaultConfig(),
 		ChainConfig:    blockchain.DefaultConfig(),
 		Version:        0,
+		MinVersion:     0,
+		UserAgent:      DefaultUserAgent,
 		PeerLimit:      20,
 		AddressLimit:   1000,
 		Port:           port,
 		VersionTimeout: time.Second * 2,
 		MaxBlockSize:   10000000,
+
+		MaxStandardTransactionSize: 100000,
+		MinRelayFee:                1,
+
+		Clock:              clock.SystemClock{},
+		MaxFutureBlockTime: time.Hour * 2,
+		MinTimeSamples:     5,
+		MaxTimeSamples:     200,
+		MaxClockAdjustment: time.Minute * 70,
+
+		SyncWindow:           16,
+		BlockDownloadTimeout: time.Second * 5,
+		SyncInterval:         time.Second * 10,
+
+		MaxTipAge:    time.Hour * 24,
+		IBDHeightLag: 144,
+
+		CompactBlocks: true,
+
+		BanThreshold: 100,
+		BanDuration:  time.Hour * 24,
+		BanListPath:  "banlist",
+
+		AddressDBPath: "addressdata",
+		PeerDBPath:    "peerdata",
+
+		AddressInterval: time.Minute * 10,
+
+		MaxOutbound:        8,
+		MaxInbound:         12,
+		ConnectionInterval: time.Second * 30,
+		PingInterval:       time.Minute * 2,
+		StaleTimeout:       time.Minute * 20,
+
+		GlobalRequestRate:    10000,
+		GlobalRequestBurst:   20000,
+		PeerRequestRate:      1000,
+		PeerRequestBurst:     2000,
+		MaxExpensiveRequests: 8,
+		MaxMessageSize:       address.MaxMessageSize,
+
+		SeenTransactionsSize: 50000,
+		SeenBlocksSize:       5000,
+		SeenTTL:              time.Hour * 24,
+
+		MempoolSize:     5000000,
+		MempoolExpiry:   time.Hour * 24 * 14,
+		MempoolPath:     "mempool.dat",
+		MaxReplacements: 100,
 	}
 	return c
 }
@@ -92,10 +335,75 @@ func NoMinerConfig(port int) *Config {
 		WalletConfig:   wallet.DefaultConfig(),
 		ChainConfig:    blockchain.DefaultConfig(),
 		Version:        1,
+		MinVersion:     1,
+		UserAgent:      DefaultUserAgent,
 		PeerLimit:      20,
 		AddressLimit:   1000,
 		Port:           port,
 		VersionTimeout: time.Second * 2,
 		MaxBlockSize:   10000000,
+
+		MaxStandardTransactionSize: 100000,
+		MinRelayFee:                1,
+
+		Clock:              clock.SystemClock{},
+		MaxFutureBlockTime: time.Hour * 2,
+		MinTimeSamples:     5,
+		MaxTimeSamples:     200,
+		MaxClockAdjustment: time.Minute * 70,
+
+		SyncWindow:           16,
+		BlockDownloadTimeout: time.Second * 5,
+		SyncInterval:         time.Second * 10,
+
+		MaxTipAge:    time.Hour * 24,
+		IBDHeightLag: 144,
+
+		CompactBlocks: true,
+
+		BanThreshold: 100,
+		BanDuration:  time.Hour * 24,
+		BanListPath:  "banlist",
+
+		AddressDBPath: "addressdata",
+		PeerDBPath:    "peerdata",
+
+		AddressInterval: time.Minute * 10,
+
+		MaxOutbound:        8,
+		MaxInbound:         12,
+		ConnectionInterval: time.Second * 30,
+		PingInterval:       time.Minute * 2,
+		StaleTimeout:       time.Minute * 20,
+
+		GlobalRequestRate:    10000,
+		GlobalRequestBurst:   20000,
+		PeerRequestRate:      1000,
+		PeerRequestBurst:     2000,
+		MaxExpensiveRequests: 8,
+		MaxMessageSize:       address.MaxMessageSize,
+
+		SeenTransactionsSize: 50000,
+		SeenBlocksSize:       5000,
+		SeenTTL:              time.Hour * 24,
+
+		MempoolSize:     5000000,
+		MempoolExpiry:   time.Hour * 24 * 14,
+		MempoolPath:     "mempool.dat",
+		MaxReplacements: 100,
 	}
 }
+
+// LightConfig is a configuration for a light client:
+// it has no miner and no full chain, only a header chain
+// and a wallet.
+// Inputs:
+// port int the port that the node should start
+// on
+func LightConfig(port int) *Config {
+	c := DefaultConfig(port)
+	c.MinerConfig.HasMiner = false
+	c.ChainConfig.HasChain = false
+	c.LightClient = true
+	return c
+}
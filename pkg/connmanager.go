@@ -0,0 +1,189 @@
+package pkg
+
+import (
+	"Coin/pkg/address/addressdb"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/utils"
+	"sync"
+	"time"
+)
+
+// maxPeerFailures is how many requests in a row may fail
+// before a peer is dropped (and replaced, if it was outbound).
+const maxPeerFailures = 3
+
+// errTooManyInbound is returned to nodes that try to become peers when we have MaxInbound inbound peers
+var errTooManyInbound = reject.New(pro.RejectCode_REJECT_NO_CAPACITY, "", "too many inbound peers")
+
+// connManager keeps track of the node's connections.
+// dialing holds the addresses the node is connecting to, so
+// that their version is known to come from an outbound peer.
+// failures maps peers to how many requests to them failed
+// in a row.
+// wake wakes up the connection manager (see requestConnections).
+type connManager struct {
+	mutex    sync.Mutex
+	dialing  map[string]bool
+	failures map[string]int
+	wake     chan struct{}
+}
+
+// isDialing returns whether the node is connecting to addr.
+func (n *Node) isDialing(addr string) bool {
+	n.conn.mutex.Lock()
+	defer n.conn.mutex.Unlock()
+	return n.conn.dialing[addr]
+}
+
+// setDialing records whether the node is connecting to addr.
+func (n *Node) setDialing(addr string, dialing bool) {
+	n.conn.mutex.Lock()
+	defer n.conn.mutex.Unlock()
+	if dialing {
+		n.conn.dialing[addr] = true
+	} else {
+		delete(n.conn.dialing, addr)
+	}
+}
+
+// countPeers returns how many outbound and inbound peers the node has.
+func (n *Node) countPeers() (outbound int, inbound int) {
+	for _, p := range n.PeerDb.List() {
+		if p.Outbound {
+			outbound++
+		} else {
+			inbound++
+		}
+	}
+	return outbound, inbound
+}
+
+// removePeer disconnects from a peer: it is removed from the
+// peer database, and its session is closed.
+func (n *Node) removePeer(addr string) {
+	n.PeerDb.Remove(addr)
+	n.Transport.Drop(addr)
+}
+
+// requestConnections wakes up the connection manager, for example
+// because a peer was dropped or new addresses were learned. It
+// never blocks.
+func (n *Node) requestConnections() {
+	select {
+	case n.conn.wake <- struct{}{}:
+	default:
+	}
+}
+
+// peerResponded records the outcome of a request to a peer (a
+// peer that responded has been seen, see pingPeers). A peer
+// that rejected the request with a code still responded. A
+// peer that fails maxPeerFailures requests in a row is dropped,
+// and the connection manager is woken up to replace it.
+func (n *Node) peerResponded(addr string, err error) {
+	if reject.Code(err) != pro.RejectCode_REJECT_NONE {
+		err = nil
+	}
+	if p := n.PeerDb.Get(addr); p != nil && err == nil {
+		p.Seen()
+	}
+	n.conn.mutex.Lock()
+	if err == nil {
+		delete(n.conn.failures, addr)
+		n.conn.mutex.Unlock()
+		return
+	}
+	n.conn.failures[addr]++
+	failed := n.conn.failures[addr] >= maxPeerFailures
+	if failed {
+		delete(n.conn.failures, addr)
+	}
+	n.conn.mutex.Unlock()
+	if failed && n.PeerDb.Get(addr) != nil {
+		utils.Debug.Printf("%v dropped unresponsive peer %v", utils.FmtAddr(n.Address), utils.FmtAddr(addr))
+		n.removePeer(addr)
+		n.requestConnections()
+	}
+}
+
+// connectionCandidates returns the addresses the connection
+// manager may dial, best first. If ConnectOnly is set, only
+// those addresses are candidates. Otherwise, the candidates are
+// the peers from before the last restart, then addresses picked
+// by the address database (see AddressDb.Select), and finally the
+// seeds. Picked addresses in a network group (see addressdb.Group)
+// that we already have an outbound peer in come after the others,
+// so that our outbound peers are spread over many networks.
+func (n *Node) connectionCandidates() []string {
+	if len(n.Config.ConnectOnly) > 0 {
+		return n.Config.ConnectOnly
+	}
+	var candidates []string
+	for _, p := range n.PeerDb.Saved() {
+		candidates = append(candidates, p.Addr.Addr)
+	}
+	groups := make(map[string]bool)
+	for _, p := range n.PeerDb.List() {
+		if p.Outbound {
+			groups[addressdb.Group(p.Addr.Addr)] = true
+		}
+	}
+	var sameGroup []string
+	for _, a := range n.AddressDB.Select(4 * n.Config.MaxOutbound) {
+		if groups[addressdb.Group(a.Addr)] {
+			sameGroup = append(sameGroup, a.Addr)
+		} else {
+			candidates = append(candidates, a.Addr)
+		}
+	}
+	candidates = append(candidates, sameGroup...)
+	return append(candidates, n.Config.Seeds...)
+}
+
+// fillOutbound dials candidates (see connectionCandidates) until
+// the node has MaxOutbound outbound peers (or is connected to
+// every ConnectOnly address), or it runs out of candidates. The
+// address database learns which dials worked (see AddressDb.Good).
+func (n *Node) fillOutbound() {
+	target := n.Config.MaxOutbound
+	if len(n.Config.ConnectOnly) > 0 {
+		target = len(n.Config.ConnectOnly)
+	}
+	outbound, _ := n.countPeers()
+	tried := make(map[string]bool)
+	for _, addr := range n.connectionCandidates() {
+		if outbound >= target {
+			return
+		}
+		if addr == n.Address || tried[addr] || n.PeerDb.Get(addr) != nil || n.BanList.IsBanned(addr) {
+			continue
+		}
+		tried[addr] = true
+		n.ConnectToPeer(addr)
+		if p := n.PeerDb.Get(addr); p != nil && p.Outbound {
+			n.AddressDB.Good(addr)
+			outbound++
+		} else {
+			n.AddressDB.Attempt(addr)
+		}
+	}
+}
+
+// runConnectionManager keeps the node connected to MaxOutbound
+// outbound peers. It dials new peers when it is woken up (see
+// requestConnections) and every ConnectionInterval, until the
+// node is killed.
+func (n *Node) runConnectionManager() {
+	ticker := time.NewTicker(n.Config.ConnectionInterval)
+	defer ticker.Stop()
+	for {
+		n.fillOutbound()
+		select {
+		case <-n.conn.wake:
+		case <-ticker.C:
+		case <-n.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"time"
+)
+
+// MaxAddresses is the most addresses sent in reply to a GetAddresses request
+const MaxAddresses = 1000
+
+// addAddresses adds addresses we heard about to the address
+// database (or updates when they were last seen). If any are
+// new, the connection manager is woken up to dial them.
+// Inputs:
+// addrs []*pro.Address the addresses
+// source string the address (or host) of the node that told us about them
+// Returns:
+// bool true if any of the addresses were new to us
+func (n *Node) addAddresses(addrs []*pro.Address, source string) bool {
+	foundNew := false
+	for _, addr := range addrs {
+		if addr.Addr == n.Address {
+			continue
+		}
+		if p := n.PeerDb.Get(addr.Addr); p != nil {
+			if p.Addr.LastSeen() < addr.LastSeen {
+				if err := n.PeerDb.UpdateLastSeen(addr.Addr, addr.LastSeen); err != nil {
+					utils.Debug.Printf("%v could not update last seen of %v", utils.FmtAddr(n.Address), utils.FmtAddr(addr.Addr))
+				}
+				foundNew = true
+			}
+			continue
+		}
+		if a := n.AddressDB.Get(addr.Addr); a != nil {
+			if a.LastSeen() < addr.LastSeen {
+				if err := n.AddressDB.UpdateLastSeen(addr.Addr, addr.LastSeen); err != nil {
+					utils.Debug.Printf("%v could not update last seen of %v", utils.FmtAddr(n.Address), utils.FmtAddr(addr.Addr))
+				}
+			}
+		} else {
+			a := address.New(addr.Addr, addr.LastSeen)
+			a.Transport = n.Transport
+			if err := n.AddressDB.Add(a, source); err == nil {
+				foundNew = true
+			}
+		}
+	}
+	if foundNew {
+		n.requestConnections()
+	}
+	return foundNew
+}
+
+// requestAddresses asks a new outbound peer for the addresses
+// it knows, and adds them (see addAddresses). While
+// we wait for the answer fGetAddr is set, and it stays set if
+// the peer sent MaxAddresses addresses, since it may know more
+// than it could send. Until a peer sends fewer than that, the
+// node keeps asking a random peer every AddressInterval.
+// The addresses are not relayed to other peers.
+func (n *Node) requestAddresses(addr *address.Address) {
+	n.addrMutex.Lock()
+	n.fGetAddr = true
+	n.addrMutex.Unlock()
+	res, err := addr.GetAddressesRPC(&pro.Empty{})
+	n.peerResponded(addr.Addr, err)
+	if err != nil {
+		utils.Debug.Printf("%v received no response from GetAddressesRPC to %v",
+			utils.FmtAddr(n.Address), utils.FmtAddr(addr.Addr))
+		return
+	}
+	utils.Debug.Printf("%v received %v addresses from %v",
+		utils.FmtAddr(n.Address), len(res.Addrs), utils.FmtAddr(addr.Addr))
+	if len(res.Addrs) < MaxAddresses {
+		n.addrMutex.Lock()
+		n.fGetAddr = false
+		n.addrMutex.Unlock()
+	}
+	n.addAddresses(res.Addrs, addr.Addr)
+}
+
+// runDiscovery re-advertises the node's address to its peers
+// every AddressInterval, and asks a random peer for more
+// addresses if the last peer that was asked may know more
+// than it sent, until the node is killed.
+func (n *Node) runDiscovery() {
+	ticker := time.NewTicker(n.Config.AddressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-n.quit:
+			return
+		}
+		n.BroadcastAddress()
+		n.addrMutex.Lock()
+		more := n.fGetAddr
+		n.addrMutex.Unlock()
+		if !more {
+			continue
+		}
+		for _, p := range n.PeerDb.GetRandom(1, nil) {
+			n.requestAddresses(p.Addr)
+		}
+	}
+}
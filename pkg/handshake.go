@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+)
+
+// MaxUserAgentLength is the longest user agent a peer may send
+const MaxUserAgentLength = 256
+
+// Versions from incompatible nodes are rejected with these errors.
+// errIncompatibleVersion is returned when either node's protocol
+// version is older than the other accepts.
+// errSelfConnection is returned when a node connects to itself.
+// errMissingServices is returned to nodes that can not serve
+// what the node needs.
+// errBadUserAgent is returned for oversized user agents.
+var (
+	errIncompatibleVersion = reject.New(pro.RejectCode_REJECT_OBSOLETE, "", "incompatible protocol version")
+	errSelfConnection      = reject.New(pro.RejectCode_REJECT_SELF_CONNECTION, "", "connected to self")
+	errMissingServices     = reject.New(pro.RejectCode_REJECT_MISSING_SERVICES, "", "missing required services")
+	errBadUserAgent        = reject.New(pro.RejectCode_REJECT_MALFORMED, "", "user agent too long")
+)
+
+// newNonce returns a random version nonce.
+func newNonce() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// checkVersion checks that the node that sent a version is
+// compatible: both nodes' protocol versions are at least the
+// minimum the other accepts, it is not the node itself, and
+// it offers the services the node needs (a light client needs
+// peers that keep the full chain).
+// Returns:
+// error why the version is rejected (nil if it is not)
+func (n *Node) checkVersion(in *pro.VersionRequest) error {
+	if in.Nonce != 0 && in.Nonce == n.nonce {
+		return errSelfConnection
+	}
+	if int(in.Version) < n.Config.MinVersion {
+		return fmt.Errorf("%w: %v is older than the minimum %v", errIncompatibleVersion, in.Version, n.Config.MinVersion)
+	}
+	if n.Config.Version < int(in.MinVersion) {
+		return fmt.Errorf("%w: %v is older than the peer's minimum %v", errIncompatibleVersion, n.Config.Version, in.MinVersion)
+	}
+	if len(in.UserAgent) > MaxUserAgentLength {
+		return errBadUserAgent
+	}
+	if n.Config.LightClient && in.Services&peer.ServiceFullChain == 0 {
+		return fmt.Errorf("%w: light clients need full chain peers", errMissingServices)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"Coin/pkg/utils"
+	"sync"
+	"time"
+)
+
+// ibdProgressInterval is how often progress is reported
+// during initial block download.
+const ibdProgressInterval = time.Second
+
+// ibdState tracks whether the node is in initial block
+// download (IBD), and how far along it is.
+// active is whether the node is in IBD.
+// pausedMiner is whether the miner was paused because of IBD.
+// startTime is when the node entered IBD.
+// startHeight is the node's height when it entered IBD.
+// lastReport is when progress was last reported.
+type ibdState struct {
+	mutex       sync.Mutex
+	active      bool
+	pausedMiner bool
+	startTime   time.Time
+	startHeight uint32
+	lastReport  time.Time
+}
+
+// SyncProgress is a snapshot of how far along initial
+// block download is.
+// Height is the height of the node's best chain.
+// BestHeight is the best height a peer has advertised.
+// Percent is how much of the best chain the node has.
+// BlocksPerSecond is how fast the node has been syncing
+// since it entered IBD.
+type SyncProgress struct {
+	Height          uint32
+	BestHeight      uint32
+	Percent         float64
+	BlocksPerSecond float64
+}
+
+// InIBD returns whether the node is in initial block download.
+// While it is, the miner is paused, and the node neither
+// relays nor accepts transactions and blocks from peers are
+// not relayed or passed to the wallet and miner.
+func (n *Node) InIBD() bool {
+	n.ibd.mutex.Lock()
+	defer n.ibd.mutex.Unlock()
+	return n.ibd.active
+}
+
+// farBehind returns whether a peer has a better chain than the
+// node, and either the node's tip is older than MaxTipAge or
+// the node is more than IBDHeightLag blocks behind.
+func (n *Node) farBehind() bool {
+	height, best := n.bestHeight(), n.BestPeerHeight()
+	if best <= height {
+		return false
+	}
+	tipAge := n.Clock.Now().Sub(time.Unix(int64(n.tipTimestamp()), 0))
+	return tipAge > n.Config.MaxTipAge || best-height > n.Config.IBDHeightLag
+}
+
+// tipTimestamp returns the timestamp of the tip of the
+// node's best chain.
+func (n *Node) tipTimestamp() uint32 {
+	n.chainMutex.RLock()
+	defer n.chainMutex.RUnlock()
+	if n.Config.LightClient {
+		return n.HeaderChain.LastHeader.Timestamp
+	}
+	return n.BlockChain.LastBlock.Header.Timestamp
+}
+
+// UpdateIBD works out whether the node is in initial block
+// download, and switches in or out of it if needed. The
+// miner is paused when the node enters IBD, and resumed (at
+// the new chain length) when it leaves.
+func (n *Node) UpdateIBD() {
+	behind := n.farBehind()
+	n.ibd.mutex.Lock()
+	defer n.ibd.mutex.Unlock()
+	if behind == n.ibd.active {
+		return
+	}
+	n.ibd.active = behind
+	if behind {
+		n.ibd.startTime = time.Now()
+		n.ibd.startHeight = n.bestHeight()
+		if n.Miner != nil && n.Miner.Active.Load() {
+			n.Miner.SetActive(false)
+			n.ibd.pausedMiner = true
+		}
+		utils.Debug.Printf("%v entered initial block download at height %v of %v",
+			utils.FmtAddr(n.Address), n.bestHeight(), n.BestPeerHeight())
+		return
+	}
+	if n.ibd.pausedMiner {
+		n.Miner.SetChainLength(n.bestHeight())
+		n.Miner.SetActive(true)
+		n.ibd.pausedMiner = false
+	}
+	utils.Debug.Printf("%v finished initial block download at height %v in %v",
+		utils.FmtAddr(n.Address), n.bestHeight(), time.Since(n.ibd.startTime).Round(time.Millisecond))
+}
+
+// SyncProgress returns how far along initial block download is.
+func (n *Node) SyncProgress() *SyncProgress {
+	n.ibd.mutex.Lock()
+	defer n.ibd.mutex.Unlock()
+	p := &SyncProgress{Height: n.bestHeight(), BestHeight: n.BestPeerHeight()}
+	if p.BestHeight <= p.Height {
+		p.Percent = 100
+	} else {
+		p.Percent = 100 * float64(p.Height) / float64(p.BestHeight)
+	}
+	if elapsed := time.Since(n.ibd.startTime).Seconds(); n.ibd.active && elapsed > 0 {
+		p.BlocksPerSecond = float64(p.Height-n.ibd.startHeight) / elapsed
+	}
+	return p
+}
+
+// reportIBDProgress logs the progress of initial block
+// download, at most once every ibdProgressInterval.
+func (n *Node) reportIBDProgress() {
+	if !n.InIBD() {
+		return
+	}
+	n.ibd.mutex.Lock()
+	report := time.Since(n.ibd.lastReport) >= ibdProgressInterval
+	if report {
+		n.ibd.lastReport = time.Now()
+	}
+	n.ibd.mutex.Unlock()
+	if report {
+		p := n.SyncProgress()
+		utils.Debug.Printf("%v initial block download at height %v of %v (%.1f%%, %.1f blocks/s)",
+			utils.FmtAddr(n.Address), p.Height, p.BestHeight, p.Percent, p.BlocksPerSecond)
+	}
+}
@@ -0,0 +1,56 @@
+package id
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// certificateLifetime is how long node certificates are valid for
+const certificateLifetime = 10 * 365 * 24 * time.Hour
+
+// Certificate returns a self-signed TLS certificate for the
+// key of an id, so that a node can prove it controls the
+// id over TLS (see VerifyCertificate).
+func Certificate(i ID) (tls.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "coin node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(certificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, i.GetPublicKey(), i.GetPrivateKey())
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: i.GetPrivateKey()}, nil
+}
+
+// VerifyCertificate checks that a certificate (in DER) is
+// a valid node certificate: self-signed, and current.
+// Returns:
+// []byte the public key of the node's id (see GetPublicKeyBytes)
+// error if the certificate is not valid
+func VerifyCertificate(der []byte) ([]byte, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, err
+	}
+	if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, errors.New("certificate is expired or not yet valid")
+	}
+	return x509.MarshalPKIXPublicKey(cert.PublicKey)
+}
@@ -93,6 +93,8 @@ func (id *SimpleID) PrivateKeyToBytes(key *ecdsa.PrivateKey) ([]byte, error) {
 	return x509Encoded, err
 }
 
+// GetPublicKeyString returns the public key in hex, which is
+// how locking scripts hold it (see block.TransactionOutput).
 func (id *SimpleID) GetPublicKeyString() string {
-	return string(id.PublicKeyBytes)
+	return hex.EncodeToString(id.PublicKeyBytes)
 }
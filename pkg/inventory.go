@@ -0,0 +1,228 @@
+package pkg
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/block"
+	"Coin/pkg/cache"
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"sync"
+	"time"
+)
+
+// MaxInventoryItems is the most items an announcement or
+// a get objects request may hold.
+const MaxInventoryItems = 1000
+
+// maxRelayTransactions is how many recently accepted
+// transactions are kept for peers to request.
+const maxRelayTransactions = 1000
+
+// inventoryRequestTimeout is how long we wait for an object
+// requested from one peer before requesting it from another
+// peer that announces it.
+const inventoryRequestTimeout = 2 * address.RPCTimeout
+
+// inventory tracks the objects the node gossips about.
+// mutex protects the inventory.
+// requested maps the hashes of objects that were requested
+// from a peer to when they were requested.
+// relay maps the hashes of recently accepted transactions to
+// the transactions, so that peers can request them.
+// relayOrder holds the hashes in relay, oldest first.
+type inventory struct {
+	mutex      sync.Mutex
+	requested  map[string]time.Time
+	relay      map[string]*block.Transaction
+	relayOrder []string
+}
+
+// SeenStats is a snapshot of how the node's seen caches
+// (SeenTransactions and SeenBlocks) have been used.
+type SeenStats struct {
+	Transactions cache.Stats
+	Blocks       cache.Stats
+}
+
+// SeenStats returns how the node's seen caches have been
+// used so far, including their hit rates and memory use.
+func (n *Node) SeenStats() *SeenStats {
+	return &SeenStats{
+		Transactions: n.SeenTransactions.Stats(),
+		Blocks:       n.SeenBlocks.Stats(),
+	}
+}
+
+// markSeen marks an object as seen.
+// Returns:
+// bool true if the object had not been seen before
+func (n *Node) markSeen(t pro.InventoryType, hash string) bool {
+	if t == pro.InventoryType_INVENTORY_BLOCK {
+		return n.SeenBlocks.Add(hash)
+	}
+	return n.SeenTransactions.Add(hash)
+}
+
+// forget marks an object as not seen (see markSeen), so that
+// it is handled again if it is relayed again.
+func (n *Node) forget(t pro.InventoryType, hash string) {
+	if t == pro.InventoryType_INVENTORY_BLOCK {
+		n.SeenBlocks.Remove(hash)
+	} else {
+		n.SeenTransactions.Remove(hash)
+	}
+}
+
+// wantObject returns whether an announced object should be
+// requested: it has not been seen, is not already being
+// requested from another peer, and the node can use it. If
+// the object is wanted, it is marked as requested.
+func (n *Node) wantObject(item *pro.InventoryItem) bool {
+	if item.Type == pro.InventoryType_INVENTORY_TRANSACTION && (n.Config.LightClient || n.InIBD()) {
+		return false
+	}
+	n.inv.mutex.Lock()
+	defer n.inv.mutex.Unlock()
+	if n.SeenBlocks.Contains(item.Hash) || n.SeenTransactions.Contains(item.Hash) {
+		return false
+	}
+	if at, ok := n.inv.requested[item.Hash]; ok && time.Since(at) < inventoryRequestTimeout {
+		return false
+	}
+	n.inv.requested[item.Hash] = time.Now()
+	return true
+}
+
+// fetchObjects requests announced objects from the peer that
+// announced them in one batch, and handles the ones it sends.
+// Blocks are requested as compact blocks if CompactBlocks is set.
+func (n *Node) fetchObjects(addr string, items []*pro.InventoryItem) {
+	defer func() {
+		n.inv.mutex.Lock()
+		for _, item := range items {
+			delete(n.inv.requested, item.Hash)
+		}
+		n.inv.mutex.Unlock()
+	}()
+	p := n.PeerDb.Get(addr)
+	if p == nil {
+		return
+	}
+	request := make([]*pro.InventoryItem, len(items))
+	for i, item := range items {
+		request[i] = item
+		if item.Type == pro.InventoryType_INVENTORY_BLOCK {
+			request[i] = &pro.InventoryItem{Type: n.blockRequestType(), Hash: item.Hash}
+		}
+	}
+	res, err := p.Addr.GetObjectsRPC(&pro.GetObjectsRequest{Items: request})
+	n.peerResponded(addr, err)
+	if err != nil {
+		utils.Debug.Printf("%v received no response from GetObjectsRPC to %v",
+			utils.FmtAddr(n.Address), utils.FmtAddr(addr))
+		return
+	}
+	wanted := make(map[string]bool)
+	for _, item := range items {
+		wanted[item.Hash] = true
+	}
+	for _, pt := range res.Transactions {
+		t := block.DecodeTransaction(pt)
+		if !wanted[t.Hash()] {
+			continue
+		}
+		if err := n.handleTransaction(t, addr); err != nil {
+			utils.Debug.Printf("%v rejected %v from %v: %v",
+				utils.FmtAddr(n.Address), t.NameTag(), utils.FmtAddr(addr), err)
+		}
+	}
+	for _, pb := range res.Blocks {
+		b := block.DecodeBlock(pb)
+		if !wanted[b.Hash()] {
+			continue
+		}
+		if err := n.handleBlock(b, addr); err != nil {
+			utils.Debug.Printf("%v rejected %v from %v: %v",
+				utils.FmtAddr(n.Address), b.NameTag(), utils.FmtAddr(addr), err)
+		}
+	}
+	for _, pcb := range res.CompactBlocks {
+		cb := block.DecodeCompactBlock(pcb)
+		if !wanted[cb.Hash()] {
+			continue
+		}
+		if err := n.handleCompactBlock(cb, addr); err != nil {
+			utils.Debug.Printf("%v rejected compact block %v from %v: %v",
+				utils.FmtAddr(n.Address), cb.Hash()[:8], utils.FmtAddr(addr), err)
+		}
+	}
+}
+
+// announce announces an object to every peer except the one
+// with address except. Transactions are only announced to
+// peers that keep the full chain.
+func (n *Node) announce(t pro.InventoryType, hash string, except string) {
+	inv := &pro.Inventory{
+		AddrMe: n.Address,
+		Items:  []*pro.InventoryItem{{Type: t, Hash: hash}},
+	}
+	for _, p := range n.PeerDb.List() {
+		if p.Addr.Addr == except {
+			continue
+		}
+		if t == pro.InventoryType_INVENTORY_TRANSACTION && !p.HasService(peer.ServiceFullChain) {
+			continue
+		}
+		go func(addr *address.Address) {
+			_, err := addr.AnnounceRPC(inv)
+			n.peerResponded(addr.Addr, err)
+			if err != nil {
+				utils.Debug.Printf("%v received no response from AnnounceRPC to %v",
+					utils.FmtAddr(n.Address), utils.FmtAddr(addr.Addr))
+			}
+		}(p.Addr)
+	}
+}
+
+// addRelayTransaction keeps a transaction for peers to request,
+// dropping the oldest one if there are too many.
+func (n *Node) addRelayTransaction(t *block.Transaction) {
+	n.inv.mutex.Lock()
+	defer n.inv.mutex.Unlock()
+	hash := t.Hash()
+	if _, ok := n.inv.relay[hash]; ok {
+		return
+	}
+	if len(n.inv.relayOrder) >= maxRelayTransactions {
+		delete(n.inv.relay, n.inv.relayOrder[0])
+		n.inv.relayOrder = n.inv.relayOrder[1:]
+	}
+	n.inv.relay[hash] = t
+	n.inv.relayOrder = append(n.inv.relayOrder, hash)
+}
+
+// relayTransaction returns a recently accepted transaction,
+// or nil if we don't have it.
+func (n *Node) relayTransaction(hash string) *block.Transaction {
+	n.inv.mutex.Lock()
+	defer n.inv.mutex.Unlock()
+	return n.inv.relay[hash]
+}
+
+// removeRelayTransaction stops offering a transaction to peers
+// (because it was replaced).
+func (n *Node) removeRelayTransaction(hash string) {
+	n.inv.mutex.Lock()
+	defer n.inv.mutex.Unlock()
+	if _, ok := n.inv.relay[hash]; !ok {
+		return
+	}
+	delete(n.inv.relay, hash)
+	for i, h := range n.inv.relayOrder {
+		if h == hash {
+			n.inv.relayOrder = append(n.inv.relayOrder[:i], n.inv.relayOrder[i+1:]...)
+			break
+		}
+	}
+}
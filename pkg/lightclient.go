@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/utils"
+	"errors"
+	"fmt"
+)
+
+// fullPeers returns the peers that advertised that they
+// store the full chain, which are the only peers a light
+// client can ask for headers and merkle proofs.
+func (n *Node) fullPeers() []*peer.Peer {
+	var peers []*peer.Peer
+	for _, p := range n.PeerDb.List() {
+		if p.HasService(peer.ServiceFullChain) {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// HandleHeader handles a header from the network on a light
+// client. The header is validated and added to the header chain.
+// Every time the best header chain grows, the wallet gets
+// another confirmation for its verified payments.
+func (n *Node) HandleHeader(h *block.Header) error {
+	if !n.CheckHeaderTime(h) {
+		return reject.New(pro.RejectCode_REJECT_BAD_TIMESTAMP, h.Hash(), "header is too far in the future")
+	}
+	n.chainMutex.Lock()
+	newTip, err := n.HeaderChain.HandleHeader(h)
+	n.chainMutex.Unlock()
+	if err != nil {
+		utils.Debug.Printf("%v received invalid header: %v", utils.FmtAddr(n.Address), err)
+		return err
+	}
+	if newTip {
+		n.reportIBDProgress()
+		n.UpdateIBD()
+	}
+	if newTip && n.Wallet != nil {
+		n.Wallet.HandleConfirmation()
+	}
+	return nil
+}
+
+// SyncHeaders brings a light client's header chain up to date.
+// It asks each full peer for the headers after its current best
+// chain, and keeps asking until a peer has nothing more to send.
+// A peer that sends an invalid header, or whose headers do not
+// make the chain any longer, is not asked again.
+func (n *Node) SyncHeaders() error {
+	peers := n.fullPeers()
+	if len(peers) == 0 {
+		return errors.New("no full peers to sync headers from")
+	}
+	utils.Debug.Printf("%v syncing headers from %v peers at height %v",
+		utils.FmtAddr(n.Address), len(peers), n.bestHeight())
+nextPeer:
+	for _, p := range peers {
+		for {
+			length := n.bestHeight()
+			n.chainMutex.RLock()
+			locator := n.HeaderChain.Locator()
+			n.chainMutex.RUnlock()
+			res, err := p.Addr.GetHeadersRPC(&pro.GetHeadersRequest{LocatorHashes: locator})
+			if err != nil {
+				utils.Debug.Printf("%v received no response from GetHeadersRPC to %v",
+					utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr))
+				break
+			}
+			for _, ph := range res.Headers {
+				if err := n.HandleHeader(block.DecodeHeader(ph)); err != nil {
+					n.misbehaving(p.Addr.Addr, peer.InvalidHeaders)
+					continue nextPeer
+				}
+			}
+			if len(res.Headers) < MaxHeadersPerResponse || n.bestHeight() == length {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyPayment checks that a transaction is in a block on the
+// light client's best header chain. It asks full peers for a
+// merkle proof of the transaction and checks the proof against
+// the merkle root of the header it already has. Once a proof
+// checks out, the transaction is handed to the wallet along with
+// the confirmations it already has.
+// Inputs:
+// tx *block.Transaction the transaction to verify
+// blockHash string the hash of the block the transaction is in
+// Returns:
+// uint32 the number of confirmations of the block
+// error if the payment could not be verified
+func (n *Node) VerifyPayment(tx *block.Transaction, blockHash string) (uint32, error) {
+	if !n.Config.LightClient {
+		return 0, errors.New("only light clients verify payments with merkle proofs")
+	}
+	header := n.HeaderChain.GetHeader(blockHash)
+	if header == nil {
+		return 0, fmt.Errorf("block %v is not in the header chain", blockHash)
+	}
+	confirmations := n.HeaderChain.Confirmations(blockHash)
+	if confirmations == 0 {
+		return 0, fmt.Errorf("block %v is not on the best header chain", blockHash)
+	}
+	txHash := tx.Hash()
+	for _, p := range n.fullPeers() {
+		res, err := p.Addr.GetMerkleProofRPC(&pro.GetMerkleProofRequest{TransactionHash: txHash, BlockHash: blockHash})
+		if err != nil || res.Proof == nil {
+			utils.Debug.Printf("%v received no merkle proof from %v: %v",
+				utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr), err)
+			continue
+		}
+		mp := block.DecodeMerkleProof(res.Proof)
+		if mp.TransactionHash != txHash || !mp.Verify(header.MerkleRoot) {
+			utils.Debug.Printf("%v received an invalid merkle proof from %v",
+				utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr))
+			continue
+		}
+		if n.Wallet != nil {
+			n.Wallet.HandleTransaction(tx, confirmations)
+		}
+		return confirmations, nil
+	}
+	return 0, errors.New("no peer could prove the payment")
+}
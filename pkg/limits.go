@@ -0,0 +1,185 @@
+package pkg
+
+import (
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/ratelimit"
+	"Coin/pkg/reject"
+	"Coin/pkg/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"net"
+	"path"
+	"sync"
+	"time"
+)
+
+// expensiveRequests are the requests that read blocks or walk
+// the chain. They cost expensiveRequestCost tokens (see admit),
+// and at most MaxExpensiveRequests of them are handled at once.
+var expensiveRequests = map[string]bool{
+	"GetBlocks":            true,
+	"GetHeaders":           true,
+	"GetData":              true,
+	"GetObjects":           true,
+	"GetMerkleProof":       true,
+	"GetBlockTransactions": true,
+}
+
+// expensiveRequestCost is how many tokens an expensive request costs (others cost 1)
+const expensiveRequestCost = 2
+
+// busyTimeout is how long an expensive request waits for the
+// others to finish before it is turned away
+const busyTimeout = time.Second
+
+// errBusy is returned for expensive requests when the node is handling too many
+var errBusy = reject.New(pro.RejectCode_REJECT_RATE_LIMITED, "", "too many requests in progress")
+
+// sessionOwner is the peer a session belongs to (see
+// bindSession).
+// addr is the address of the peer ("" until it is bound).
+// mutex guards addr, since the session's requests are
+// handled concurrently.
+type sessionOwner struct {
+	addr  string
+	mutex sync.Mutex
+}
+
+// sessionOwnerKey is the context key of the sessionOwner of
+// the requests that arrive on a session.
+type sessionOwnerKey struct{}
+
+// withSessionOwner returns the context of the requests of a
+// new session, which no peer owns yet.
+func withSessionOwner(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionOwnerKey{}, &sessionOwner{})
+}
+
+// bindSession records that the session a request arrived on
+// belongs to the peer at addr, so that the peer answers for
+// the requests on it (see requestSource). A session is only
+// bound to a peer that connects from the host of its address,
+// so a node can not pass its requests off as another's.
+func bindSession(ctx context.Context, addr string) {
+	o, ok := ctx.Value(sessionOwnerKey{}).(*sessionOwner)
+	if !ok || !fromHost(ctx, addr) {
+		return
+	}
+	o.mutex.Lock()
+	o.addr = addr
+	o.mutex.Unlock()
+}
+
+// sessionPeer returns the address of the peer that the session
+// a request arrived on belongs to ("" if it belongs to none).
+func sessionPeer(ctx context.Context) string {
+	o, ok := ctx.Value(sessionOwnerKey{}).(*sessionOwner)
+	if !ok {
+		return ""
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.addr
+}
+
+// fromHost returns whether a request came from the host of addr.
+func fromHost(ctx context.Context, addr string) bool {
+	remote := remoteHost(ctx)
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || remote == "" {
+		return false
+	}
+	if host == remote {
+		return true
+	}
+	ips, err := net.LookupHost(host)
+	if err != nil {
+		return false
+	}
+	for _, ip := range ips {
+		if ip == remote {
+			return true
+		}
+	}
+	return false
+}
+
+// requestSource returns who sent a request: the peer it comes
+// from, if the peer proved its identity (see authenticatedPeer)
+// or the request arrived on the peer's session (see
+// bindSession), or else the host it came from. Requests are
+// never limited by the address they claim to come from, since
+// anyone can claim any address, nor by the port they come
+// from, since a node can connect from as many ports as it likes.
+// Returns:
+// string the key the request is limited by
+// string the address of the peer that sent it ("" if none)
+func (n *Node) requestSource(ctx context.Context, req interface{}) (string, string) {
+	claimed := ""
+	if r, ok := req.(interface{ GetAddrMe() string }); ok {
+		claimed = r.GetAddrMe()
+	}
+	from := n.authenticatedPeer(ctx, claimed)
+	if from == "" {
+		if addr := sessionPeer(ctx); addr != "" && n.PeerDb.Get(addr) != nil {
+			from = addr
+		}
+	}
+	if from != "" {
+		return from, from
+	}
+	return remoteHost(ctx), ""
+}
+
+// admit decides whether the node handles a request. Every
+// request takes tokens from its sender's bucket and the
+// global bucket (see Config.PeerRequestRate), and a known
+// peer that goes over its limit is misbehaving (see
+// requestSource). Expensive requests also wait for one of
+// MaxExpensiveRequests slots.
+// Inputs:
+// method string the name of the rpc that handles the request
+// req interface{} the request
+// Returns:
+// func() releases what the request holds once it is handled
+// error why the request is turned away (nil if it is not)
+func (n *Node) admit(ctx context.Context, method string, req interface{}) (func(), error) {
+	key, from := n.requestSource(ctx, req)
+	cost := 1.0
+	if expensiveRequests[method] {
+		cost = expensiveRequestCost
+	}
+	if err := n.limiter.Allow(key, cost); err != nil {
+		utils.Debug.Printf("%v turned away a %v request from %v: %v",
+			utils.FmtAddr(n.Address), method, key, err)
+		if err == ratelimit.ErrKeyLimit && from != "" {
+			n.misbehaving(from, peer.ExceededRateLimit)
+		}
+		return nil, reject.New(pro.RejectCode_REJECT_RATE_LIMITED, "", err.Error())
+	}
+	if !expensiveRequests[method] {
+		return func() {}, nil
+	}
+	timer := time.NewTimer(busyTimeout)
+	defer timer.Stop()
+	select {
+	case n.expensive <- struct{}{}:
+		return func() { <-n.expensive }, nil
+	case <-timer.C:
+		return nil, errBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limitRequests is a unary server interceptor that only hands
+// the requests the node admits (see admit) to their handlers.
+func (n *Node) limitRequests(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	release, err := n.admit(ctx, path.Base(info.FullMethod), req)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return handler(ctx, req)
+}
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/mempool"
+	"Coin/pkg/utils"
+)
+
+// acceptTransaction validates a transaction against the main
+// chain and the mempool, and adds it to the mempool. The
+// transactions it replaces (see mempool.Mempool.Add) are no
+// longer offered to peers, and the wallet is told about them.
+// The miner is told when the mempool changes.
+// Inputs:
+// t *block.Transaction the transaction
+// Returns:
+// error why the transaction was not accepted, with a reject
+// code (nil if it was)
+func (n *Node) acceptTransaction(t *block.Transaction) error {
+	replaced, err := n.Mempool.Add(n.validationContext(""), t)
+	if err != nil {
+		return err
+	}
+	for _, old := range replaced {
+		utils.Debug.Printf("%v replaced %v with %v", utils.FmtAddr(n.Address), old.NameTag(), t.NameTag())
+		n.removeRelayTransaction(old.Hash())
+		if n.Wallet != nil {
+			n.Wallet.HandleReplacement(old, t)
+		}
+	}
+	if n.Miner != nil {
+		n.Miner.HandleTransaction(t)
+	}
+	return nil
+}
+
+// updateMempool updates the mempool after the main chain
+// changed: the transactions of connected blocks are removed,
+// along with the transactions that conflict with them, and the
+// transactions of disconnected blocks are added back. The miner
+// is told about connected blocks.
+// Inputs:
+// u *blockchain.Update how the main chain changed (may be nil)
+func (n *Node) updateMempool(u *blockchain.Update) {
+	if u == nil || n.Mempool == nil {
+		return
+	}
+	if len(u.Disconnected) > 0 {
+		n.Mempool.Reorg(n.validationContext(""), u.Disconnected, u.Connected)
+		utils.Debug.Printf("%v updated its mempool after a reorg of %v blocks",
+			utils.FmtAddr(n.Address), len(u.Disconnected))
+	} else {
+		for _, b := range u.Connected {
+			for _, t := range n.Mempool.ConnectBlock(b) {
+				utils.Debug.Printf("%v dropped %v, which conflicts with %v",
+					utils.FmtAddr(n.Address), t.NameTag(), b.NameTag())
+			}
+		}
+		n.Mempool.Expire(n.Clock.Now())
+	}
+}
+
+// BumpFee replaces a payment of the wallet that is not on the
+// chain yet with one that pays a higher fee (see
+// wallet.Wallet.BumpFee), and broadcasts the replacement.
+// Inputs:
+// hash string the hash of the payment
+// fee uint32 the new fee
+// Returns:
+// *block.Transaction the replacement (nil if the payment can
+// not be replaced, or the mempool rejected the replacement)
+func (n *Node) BumpFee(hash string, fee uint32) *block.Transaction {
+	if n.Wallet == nil {
+		return nil
+	}
+	t := n.Wallet.BumpFee(hash, fee)
+	if t == nil || n.BroadcastTransaction(t) != nil {
+		return nil
+	}
+	return t
+}
+
+// loadMempool adds the transactions stored by the node's last
+// run back to its mempool (see mempool.Mempool.Load), checking
+// them against the current tip of the main chain.
+func (n *Node) loadMempool() {
+	if n.Mempool == nil || n.Config.MempoolPath == "" {
+		return
+	}
+	loaded, dropped, err := n.Mempool.Load(n.validationContext(""), n.Config.MempoolPath)
+	if err != nil {
+		utils.Debug.Printf("%v could not load its mempool from {%v}: %v",
+			utils.FmtAddr(n.Address), n.Config.MempoolPath, err)
+		return
+	}
+	utils.Debug.Printf("%v loaded %v transactions into its mempool and dropped %v",
+		utils.FmtAddr(n.Address), loaded, dropped)
+}
+
+// saveMempool stores the node's mempool, so that its next run
+// can load it (see loadMempool).
+func (n *Node) saveMempool() {
+	if n.Mempool == nil || n.Config.MempoolPath == "" {
+		return
+	}
+	if err := n.Mempool.Dump(n.Config.MempoolPath); err != nil {
+		utils.Debug.Printf("%v could not store its mempool at {%v}: %v",
+			utils.FmtAddr(n.Address), n.Config.MempoolPath, err)
+	}
+}
+
+// MempoolStats returns a snapshot of the node's mempool (light
+// clients have none).
+func (n *Node) MempoolStats() mempool.Stats {
+	if n.Mempool == nil {
+		return mempool.Stats{}
+	}
+	return n.Mempool.Stats()
+}
@@ -0,0 +1,565 @@
+package mempool
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain/coindatabase"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/validation"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry is a transaction in the mempool.
+// Transaction is the transaction.
+// Hash is the transaction's hash.
+// Fee is what its inputs are worth beyond its outputs.
+// Size is its size in bytes.
+// Added is when it entered the mempool.
+// seq orders entries by when they were added, so that
+// parents come before their children.
+type Entry struct {
+	Transaction *block.Transaction
+	Hash        string
+	Fee         uint64
+	Size        uint32
+	Added       time.Time
+
+	seq uint64
+}
+
+// lowerFeeRate returns whether entry a pays less per byte
+// than entry b.
+func lowerFeeRate(a, b *Entry) bool {
+	return a.Fee*uint64(b.Size) < b.Fee*uint64(a.Size)
+}
+
+// Mempool holds the valid transactions that are not on the
+// main chain yet. Transactions may spend the outputs of other
+// transactions in the mempool (their parents), but no two may
+// spend the same coin. It is safe for concurrent use.
+// MaxSize is the most bytes of transactions it holds. Once it
+// is full, the transactions that pay the least per byte are
+// evicted.
+// Expiry is how long a transaction may stay (0 means forever).
+// MaxReplacements is the most transactions a replacement may
+// evict, counting their descendants (see Add).
+// entries maps hashes to the entries.
+// spends maps the coins that entries spend to the hashes of
+// the entries that spend them.
+// size is the bytes of all entries.
+// seq is the seq of the next entry.
+// evicted, expired, conflicted and replaced count the entries
+// removed for each reason, and resurrected the transactions of
+// disconnected blocks that were added back (see Stats).
+type Mempool struct {
+	MaxSize         uint32
+	Expiry          time.Duration
+	MaxReplacements int
+
+	mutex   sync.Mutex
+	entries map[string]*Entry
+	spends  map[coindatabase.CoinLocator]string
+	size    uint32
+	seq     uint64
+
+	evicted     uint64
+	expired     uint64
+	conflicted  uint64
+	replaced    uint64
+	resurrected uint64
+}
+
+// Stats is a snapshot of a Mempool.
+// Transactions is how many transactions it holds, and Size
+// how many bytes they take.
+// Fees is what the transactions pay together.
+// Evicted counts the transactions dropped to stay within
+// MaxSize, Expired those dropped for being older than the
+// Expiry, Conflicted those dropped because a block spent their
+// coins, and Replaced those (and their descendants) that a
+// transaction paying more replaced.
+// Resurrected counts the transactions of disconnected blocks
+// that were added back.
+type Stats struct {
+	Transactions int
+	Size         uint32
+	Fees         uint64
+	Evicted      uint64
+	Expired      uint64
+	Conflicted   uint64
+	Replaced     uint64
+	Resurrected  uint64
+}
+
+// New returns an empty Mempool that holds up to maxSize bytes
+// of transactions for up to expiry, and lets a replacement evict
+// up to maxReplacements transactions.
+func New(maxSize uint32, expiry time.Duration, maxReplacements int) *Mempool {
+	return &Mempool{
+		MaxSize:         maxSize,
+		Expiry:          expiry,
+		MaxReplacements: maxReplacements,
+		entries:         make(map[string]*Entry),
+		spends:          make(map[coindatabase.CoinLocator]string),
+	}
+}
+
+func locate(txi *block.TransactionInput) coindatabase.CoinLocator {
+	return coindatabase.CoinLocator{
+		ReferenceTransactionHash: txi.ReferenceTransactionHash,
+		OutputIndex:              txi.OutputIndex,
+	}
+}
+
+// Add validates a transaction and adds it to the mempool. The
+// transaction is checked against the TransactionRules, with the
+// coins of c and the outputs of the transactions in the mempool.
+// Entries older than the Expiry are removed first.
+// A transaction that spends coins that transactions in the
+// mempool spend replaces them (replace-by-fee) if:
+// (1) it pays a higher fee rate than each of them,
+// (2) it pays a higher fee than they and their descendants,
+// which are evicted with them, pay together,
+// (3) it evicts at most MaxReplacements transactions, and
+// (4) it does not spend the outputs of the ones it evicts.
+// Inputs:
+// c *validation.Context the context of the main chain (its Now
+// is when the transaction is added)
+// tx *block.Transaction the transaction
+// Returns:
+// []*block.Transaction the transactions it replaced
+// error why the transaction was not added, with a reject code
+// (nil if it was)
+func (mp *Mempool) Add(c *validation.Context, tx *block.Transaction) ([]*block.Transaction, error) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	mp.expire(c.Now)
+	return mp.add(c, tx, c.Now)
+}
+
+// add adds a transaction that entered the mempool at added,
+// replacing the entries it conflicts with, and then evicts
+// entries until the mempool is within MaxSize. If that evicts
+// the transaction itself, the mempool is left as it was. The
+// mempool must be locked.
+func (mp *Mempool) add(c *validation.Context, tx *block.Transaction, added time.Time) ([]*block.Transaction, error) {
+	hash := tx.Hash()
+	if mp.entries[hash] != nil {
+		return nil, reject.New(pro.RejectCode_REJECT_DUPLICATE, hash, "transaction already in mempool")
+	}
+	view := mp.view(c)
+	if err := validation.CheckTransaction(view, tx, validation.TransactionRules); err != nil {
+		return nil, err
+	}
+	fee, err := validation.Fee(view.Coins, tx)
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{Transaction: tx, Hash: hash, Fee: fee, Size: tx.Size(), Added: added, seq: mp.seq}
+	evicted, err := mp.checkReplacement(e)
+	if err != nil {
+		return nil, err
+	}
+	for _, old := range evicted {
+		mp.remove(old)
+	}
+	mp.insert(e)
+	trimmed := mp.trim()
+	if mp.entries[hash] == nil {
+		// the transaction does not fit, so the mempool stays as it was
+		for _, t := range trimmed {
+			if t != e {
+				mp.insert(t)
+			}
+		}
+		for _, old := range evicted {
+			mp.insert(old)
+		}
+		mp.evicted -= uint64(len(trimmed))
+		return nil, &validation.Error{
+			Rule:   "mempool-full",
+			Kind:   validation.Policy,
+			Reject: reject.New(pro.RejectCode_REJECT_INSUFFICIENT_FEE, hash, "fee rate too low to enter the full mempool"),
+		}
+	}
+	var replaced []*block.Transaction
+	for _, old := range evicted {
+		replaced = append(replaced, old.Transaction)
+	}
+	mp.replaced += uint64(len(evicted))
+	mp.seq++
+	return replaced, nil
+}
+
+// checkReplacement checks whether a new entry may replace the
+// entries it conflicts with (see Add). The mempool must be
+// locked.
+// Returns:
+// []*Entry the entries it replaces and their descendants,
+// parents first
+// error why it may not replace them, with a reject code
+func (mp *Mempool) checkReplacement(e *Entry) ([]*Entry, error) {
+	broken := func(code pro.RejectCode, format string, args ...interface{}) error {
+		return &validation.Error{Rule: "replace-by-fee", Kind: validation.Policy, Reject: reject.Errorf(code, e.Hash, format, args...)}
+	}
+	var conflicts []*Entry
+	for _, txi := range e.Transaction.Inputs {
+		if other, ok := mp.spends[locate(txi)]; ok {
+			conflict := mp.entries[other]
+			if !lowerFeeRate(conflict, e) {
+				return nil, broken(pro.RejectCode_REJECT_INSUFFICIENT_FEE,
+					"fee rate is not above the fee rate of %.8v, which it replaces", other)
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+	evicting := make(map[string]bool)
+	var evicted []*Entry
+	for _, conflict := range conflicts {
+		for _, d := range mp.descendants(conflict) {
+			if !evicting[d.Hash] {
+				evicting[d.Hash] = true
+				evicted = append(evicted, d)
+			}
+		}
+	}
+	if len(evicted) > mp.MaxReplacements {
+		return nil, broken(pro.RejectCode_REJECT_DOUBLE_SPEND,
+			"would evict %v transactions, more than the maximum %v", len(evicted), mp.MaxReplacements)
+	}
+	var fees uint64
+	for _, old := range evicted {
+		fees += old.Fee
+	}
+	if e.Fee <= fees {
+		return nil, broken(pro.RejectCode_REJECT_INSUFFICIENT_FEE,
+			"fee %v is not above the %v the transactions it replaces pay", e.Fee, fees)
+	}
+	for _, txi := range e.Transaction.Inputs {
+		if evicting[txi.ReferenceTransactionHash] {
+			return nil, broken(pro.RejectCode_REJECT_INVALID,
+				"spends an output of %.8v, which it replaces", txi.ReferenceTransactionHash)
+		}
+	}
+	return evicted, nil
+}
+
+// descendants returns an entry and every entry that spends its
+// outputs, directly or not, parents first. The mempool must be
+// locked.
+func (mp *Mempool) descendants(e *Entry) []*Entry {
+	family := []*Entry{e}
+	for i := range e.Transaction.Outputs {
+		loc := coindatabase.CoinLocator{ReferenceTransactionHash: e.Hash, OutputIndex: uint32(i)}
+		if child := mp.entries[mp.spends[loc]]; child != nil {
+			family = append(family, mp.descendants(child)...)
+		}
+	}
+	return family
+}
+
+// view returns c with the coins of the main chain and the
+// outputs of the mempool's transactions.
+func (mp *Mempool) view(c *validation.Context) *validation.Context {
+	view := *c
+	view.Coins = &poolCoins{mp: mp, chain: c.Coins}
+	return &view
+}
+
+// poolCoins are the coins of the main chain and the outputs of
+// the mempool's transactions. The mempool must be locked while
+// they are used.
+type poolCoins struct {
+	mp    *Mempool
+	chain validation.Coins
+}
+
+func (pc *poolCoins) Coin(txi *block.TransactionInput) (*block.TransactionOutput, error) {
+	if parent := pc.mp.entries[txi.ReferenceTransactionHash]; parent != nil {
+		if int(txi.OutputIndex) >= len(parent.Transaction.Outputs) {
+			return nil, reject.New(pro.RejectCode_REJECT_MISSING_INPUTS, "", "output index out of range")
+		}
+		return parent.Transaction.Outputs[txi.OutputIndex], nil
+	}
+	if pc.chain == nil {
+		return nil, reject.New(pro.RejectCode_REJECT_MISSING_INPUTS, "", "no coins to spend")
+	}
+	return pc.chain.Coin(txi)
+}
+
+// insert adds an entry. The mempool must be locked.
+func (mp *Mempool) insert(e *Entry) {
+	mp.entries[e.Hash] = e
+	for _, txi := range e.Transaction.Inputs {
+		mp.spends[locate(txi)] = e.Hash
+	}
+	mp.size += e.Size
+}
+
+// remove removes an entry (but not its children). The mempool
+// must be locked.
+func (mp *Mempool) remove(e *Entry) {
+	delete(mp.entries, e.Hash)
+	for _, txi := range e.Transaction.Inputs {
+		delete(mp.spends, locate(txi))
+	}
+	mp.size -= e.Size
+}
+
+// removeWithDescendants removes an entry and every entry that
+// spends its outputs, directly or not. The mempool must be
+// locked.
+// Returns:
+// int how many entries were removed
+func (mp *Mempool) removeWithDescendants(e *Entry) int {
+	mp.remove(e)
+	removed := 1
+	for i := range e.Transaction.Outputs {
+		loc := coindatabase.CoinLocator{ReferenceTransactionHash: e.Hash, OutputIndex: uint32(i)}
+		if child := mp.entries[mp.spends[loc]]; child != nil {
+			removed += mp.removeWithDescendants(child)
+		}
+	}
+	return removed
+}
+
+// hasChildren returns whether an entry's outputs are spent by
+// other entries. The mempool must be locked.
+func (mp *Mempool) hasChildren(e *Entry) bool {
+	for i := range e.Transaction.Outputs {
+		loc := coindatabase.CoinLocator{ReferenceTransactionHash: e.Hash, OutputIndex: uint32(i)}
+		if _, ok := mp.spends[loc]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// trim evicts the entries without children that pay the least
+// per byte until the mempool is within MaxSize (parents are only
+// evicted once their children are, so a child can pay for its
+// parent). The mempool must be locked.
+// Returns:
+// []*Entry the evicted entries
+func (mp *Mempool) trim() []*Entry {
+	var trimmed []*Entry
+	for mp.MaxSize > 0 && mp.size > mp.MaxSize {
+		var worst *Entry
+		for _, e := range mp.entries {
+			if !mp.hasChildren(e) && (worst == nil || lowerFeeRate(e, worst)) {
+				worst = e
+			}
+		}
+		mp.remove(worst)
+		mp.evicted++
+		trimmed = append(trimmed, worst)
+	}
+	return trimmed
+}
+
+// expire removes the entries that entered the mempool more
+// than Expiry before now (and their descendants). The mempool
+// must be locked.
+func (mp *Mempool) expire(now time.Time) {
+	if mp.Expiry == 0 || now.IsZero() {
+		return
+	}
+	for _, e := range mp.sorted() {
+		if mp.entries[e.Hash] != nil && now.Sub(e.Added) > mp.Expiry {
+			mp.expired += uint64(mp.removeWithDescendants(e))
+		}
+	}
+}
+
+// Expire removes the transactions that entered the mempool more
+// than Expiry before now, along with the transactions that spend
+// their outputs.
+func (mp *Mempool) Expire(now time.Time) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	mp.expire(now)
+}
+
+// sorted returns the entries in the order they were added, so
+// parents come before their children. The mempool must be
+// locked.
+func (mp *Mempool) sorted() []*Entry {
+	entries := make([]*Entry, 0, len(mp.entries))
+	for _, e := range mp.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	return entries
+}
+
+// ConnectBlock removes the transactions of a block that was
+// added to the main chain, and the transactions that spend the
+// same coins as the block's transactions (along with their
+// descendants, which can never be valid now).
+// Returns:
+// []*block.Transaction the transactions removed for conflicting
+// with the block
+func (mp *Mempool) ConnectBlock(b *block.Block) []*block.Transaction {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	return mp.connectBlock(b)
+}
+
+// connectBlock removes a block's transactions and the entries
+// that conflict with them. The mempool must be locked.
+func (mp *Mempool) connectBlock(b *block.Block) []*block.Transaction {
+	var conflicts []*block.Transaction
+	for _, tx := range b.Transactions {
+		hash := tx.Hash()
+		if e := mp.entries[hash]; e != nil {
+			mp.remove(e)
+		}
+		for _, txi := range tx.Inputs {
+			other, ok := mp.spends[locate(txi)]
+			if !ok || other == hash {
+				continue
+			}
+			e := mp.entries[other]
+			mp.conflicted += uint64(mp.removeWithDescendants(e))
+			conflicts = append(conflicts, e.Transaction)
+		}
+	}
+	return conflicts
+}
+
+// Reorg updates the mempool after the main chain switched
+// branches. The transactions of the connected blocks are removed
+// (see ConnectBlock), and then the transactions of the
+// disconnected blocks are added back, before the transactions
+// that were already in the mempool. Everything is checked again
+// against c, so transactions that are no longer valid are dropped.
+// Inputs:
+// c *validation.Context the context of the new main chain
+// disconnected []*block.Block the blocks that left the main chain,
+// newest first
+// connected []*block.Block the blocks that joined it, oldest first
+func (mp *Mempool) Reorg(c *validation.Context, disconnected, connected []*block.Block) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	for _, b := range connected {
+		mp.connectBlock(b)
+	}
+	pending := mp.sorted()
+	mp.entries = make(map[string]*Entry)
+	mp.spends = make(map[coindatabase.CoinLocator]string)
+	mp.size = 0
+	for i := len(disconnected) - 1; i >= 0; i-- {
+		for _, tx := range disconnected[i].Transactions {
+			if tx.IsCoinbase() {
+				continue
+			}
+			if _, err := mp.add(c, tx, c.Now); err == nil {
+				mp.resurrected++
+			}
+		}
+	}
+	for _, e := range pending {
+		if _, err := mp.add(c, e.Transaction, e.Added); err != nil {
+			mp.conflicted++
+		}
+	}
+	mp.expire(c.Now)
+}
+
+// Select picks the transactions to mine: those that pay the
+// most per byte, up to maxSize bytes. A transaction is only
+// picked after its parents in the mempool, so the selection can
+// be put on a block in order.
+// Returns:
+// []*Entry the picked entries, in an order a block may hold them
+func (mp *Mempool) Select(maxSize uint32) []*Entry {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	candidates := mp.sorted()
+	sort.SliceStable(candidates, func(i, j int) bool { return lowerFeeRate(candidates[j], candidates[i]) })
+	picked := make(map[string]bool)
+	var selected []*Entry
+	var size uint32
+	for progress := true; progress; {
+		progress = false
+		for _, e := range candidates {
+			if picked[e.Hash] || size+e.Size > maxSize || !mp.parentsPicked(e, picked) {
+				continue
+			}
+			picked[e.Hash] = true
+			selected = append(selected, e)
+			size += e.Size
+			progress = true
+		}
+	}
+	return selected
+}
+
+// parentsPicked returns whether every parent of an entry that
+// is in the mempool has been picked. The mempool must be locked.
+func (mp *Mempool) parentsPicked(e *Entry, picked map[string]bool) bool {
+	for _, txi := range e.Transaction.Inputs {
+		if mp.entries[txi.ReferenceTransactionHash] != nil && !picked[txi.ReferenceTransactionHash] {
+			return false
+		}
+	}
+	return true
+}
+
+// Has returns whether a transaction is in the mempool.
+func (mp *Mempool) Has(hash string) bool {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	return mp.entries[hash] != nil
+}
+
+// Get returns the entry of a transaction (nil if it is not in
+// the mempool).
+func (mp *Mempool) Get(hash string) *Entry {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	return mp.entries[hash]
+}
+
+// Transactions returns the transactions in the mempool, parents
+// before their children.
+func (mp *Mempool) Transactions() []*block.Transaction {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	var txs []*block.Transaction
+	for _, e := range mp.sorted() {
+		txs = append(txs, e.Transaction)
+	}
+	return txs
+}
+
+// Count returns how many transactions are in the mempool.
+func (mp *Mempool) Count() int {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	return len(mp.entries)
+}
+
+// Stats returns a snapshot of the mempool.
+func (mp *Mempool) Stats() Stats {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	s := Stats{
+		Transactions: len(mp.entries),
+		Size:         mp.size,
+		Evicted:      mp.evicted,
+		Expired:      mp.expired,
+		Conflicted:   mp.conflicted,
+		Replaced:     mp.replaced,
+		Resurrected:  mp.resurrected,
+	}
+	for _, e := range mp.entries {
+		s.Fees += e.Fee
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package mempool
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/pro"
+	"Coin/pkg/validation"
+	"google.golang.org/protobuf/proto"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// Dump writes the transactions in the mempool to the file at
+// path, parents first, along with when they entered the mempool.
+// The file is written next to path first and then moved over it,
+// so an interrupted dump leaves the previous one in place.
+// Inputs:
+// path string where the mempool is stored
+// Returns:
+// error why the mempool could not be stored (nil if it was)
+func (mp *Mempool) Dump(path string) error {
+	mp.mutex.Lock()
+	dump := &pro.MempoolDump{}
+	for _, e := range mp.sorted() {
+		dump.Records = append(dump.Records, &pro.MempoolRecord{
+			Transaction: block.EncodeTransaction(e.Transaction),
+			Added:       e.Added.Unix(),
+		})
+	}
+	mp.mutex.Unlock()
+	bytes, err := proto.Marshal(dump)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, bytes, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load adds the transactions stored by Dump back to the mempool.
+// Each one is checked again against c, as the main chain may
+// have moved on since it was stored, and keeps the time it first
+// entered the mempool. Transactions older than the Expiry are
+// dropped (and counted as expired). A missing file is an empty
+// mempool.
+// Inputs:
+// c *validation.Context the context of the main chain
+// path string where the mempool is stored
+// Returns:
+// int how many transactions were added back
+// int how many were dropped, for being too old or no longer
+// valid
+// error why the file could not be read (nil if it was)
+func (mp *Mempool) Load(c *validation.Context, path string) (int, int, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	dump := &pro.MempoolDump{}
+	if err = proto.Unmarshal(bytes, dump); err != nil {
+		return 0, 0, err
+	}
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	loaded, dropped := 0, 0
+	for _, r := range dump.Records {
+		added := time.Unix(r.Added, 0)
+		if mp.Expiry != 0 && c.Now.Sub(added) > mp.Expiry {
+			mp.expired++
+			dropped++
+			continue
+		}
+		if _, err := mp.add(c, block.DecodeTransaction(r.Transaction), added); err != nil {
+			dropped++
+			continue
+		}
+		loaded++
+	}
+	return loaded, dropped, nil
+}
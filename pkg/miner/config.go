@@ -13,8 +13,6 @@ import (
 // of the node.
 // DefineLockTime defines the lock
 // time that should be on the coinbase transaction.
-// TransactionPoolCapacity defines the maximum number of
-// transactions allowed in the transaction pool.
 // PriorityLimit defines the priority threshold that
 // must be met for the miner to start mining a
 // group of transactions
@@ -42,8 +40,7 @@ type Config struct {
 	Version        uint32
 	DefineLockTime uint32
 
-	TransactionPoolCapacity uint32
-	PriorityLimit           uint32
+	PriorityLimit uint32
 
 	BlockSize  uint32
 	NonceLimit uint32
@@ -58,16 +55,15 @@ type Config struct {
 // for the Miner.
 func DefaultConfig(powdNumZeros int) *Config {
 	return &Config{
-		HasMiner:                true,
-		Version:                 0,
-		DefineLockTime:          0,
-		TransactionPoolCapacity: 50,
-		PriorityLimit:           10,
-		BlockSize:               1000,
-		NonceLimit:              uint32(math.Pow(2, 20)),
-		InitialSubsidy:          50,
-		SubsidyHalvingRate:      10,
-		MaxHalvings:             10,
-		InitialPOWDifficulty:    utils.CalcPOWD(powdNumZeros),
+		HasMiner:             true,
+		Version:              0,
+		DefineLockTime:       0,
+		PriorityLimit:        10,
+		BlockSize:            1000,
+		NonceLimit:           uint32(math.Pow(2, 20)),
+		InitialSubsidy:       50,
+		SubsidyHalvingRate:   10,
+		MaxHalvings:          10,
+		InitialPOWDifficulty: utils.CalcPOWD(powdNumZeros),
 	}
 }
@@ -4,41 +4,40 @@ import (
 	"Coin/pkg/block"
 	"bytes"
 	"context"
-	"fmt"
 	"math"
-	"time"
 )
 
 // Mine When asked to mine, the miner selects the transactions
-// with the highest priority to add to the mining pool.
+// with the highest priority from the mempool to add to the
+// mining pool.
 func (m *Miner) Mine() *block.Block {
-	//TODO
-	if !m.TxPool.PriorityMet() { // return if not worth mining a block
+	pool := m.NewMiningPool()                     // create new pool
+	if pool.Priority() < m.Config.PriorityLimit { // return if not worth mining a block
 		return nil
 	}
 	m.Mining.Store(true) // set mining to true
-	//m.MiningPool = m.NewMiningPool() // create new pool
-	pool := m.NewMiningPool() // create new pool
-	b := block.New(m.PreviousHash, pool, string(m.DifficultyTarget))
-	//ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	m.MiningPool = pool
+	// the coinbase has to be on the block before the merkle root is calculated
+	txs := append([]*block.Transaction{m.GenerateCoinbaseTransaction(pool)}, pool.Transactions()...)
+	b := block.New(m.PreviousHash, txs, string(m.DifficultyTarget), uint32(m.Clock.Now().Unix()))
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel() // helps context exit
 	// calculate nonce and check if it is true or not
 	nonceFound := m.CalculateNonce(ctx, b)
 	m.Mining.Store(false) // update mining field
-	if nonceFound {
-		m.SendBlock <- b // send block to miner channel
-		//m.HandleBlock(b)
-		m.TxPool.CheckTransactions(b.Transactions)
-		b.Transactions = append([]*block.Transaction{m.GenerateCoinbaseTransaction(pool)}, pool...)
-		return b
+	if !nonceFound {
+		return nil
 	}
-	return nil
+	m.SendBlock <- b // send block to miner channel
+	return b
 }
 
 // CalculateNonce finds a winning nonce for a block. It uses context to
 // know whether it should quit before it finds a nonce (if another block
 // was found). ASICSs are optimized for this task.
+// Returns:
+// bool whether a nonce that meets the target was found before
+// the nonce limit ran out
 func (m *Miner) CalculateNonce(ctx context.Context, b *block.Block) bool {
 	nonce := uint32(0)
 	target := m.DifficultyTarget
@@ -61,29 +60,15 @@ func (m *Miner) CalculateNonce(ctx context.Context, b *block.Block) bool {
 			}
 		}
 	}
-	return true
+	return false // ran out of nonces without meeting the target
 }
 
 // GenerateCoinbaseTransaction generates a coinbase
 // transaction based off the transactions in the mining pool.
 // It does this by combining the fee reward to the minting reward,
 // and sending that sum to itself.
-func (m *Miner) GenerateCoinbaseTransaction(txs []*block.Transaction) *block.Transaction {
-	//TODO
-
-	inpSum, _ := m.getInputSums(txs)
-	var outSum []uint32
-	var fee uint32
-	//reward := m.CalculateMintingReward()
-	for _, tx := range txs {
-		//reward += (inpSum[i] - tx.SumOutputs())
-		outSum = append(outSum, tx.SumOutputs())
-	}
-	for i, sum := range inpSum {
-		fee += sum - outSum[i] // aggregate
-	}
-	// take care of case where they are equal
-	reward := m.CalculateMintingReward() + fee // add fee reward to minting reward
+func (m *Miner) GenerateCoinbaseTransaction(pool MiningPool) *block.Transaction {
+	reward := m.CalculateMintingReward() + pool.Fees() // add fee reward to minting reward
 	new_tx := &block.Transaction{
 		Version:  0,
 		Inputs:   make([]*block.TransactionInput, 0),
@@ -93,30 +78,6 @@ func (m *Miner) GenerateCoinbaseTransaction(txs []*block.Transaction) *block.Tra
 	return new_tx
 }
 
-// getInputSums returns the sums of the inputs of a slice of transactions,
-// as well as an error if the function fails. This function sends a request to
-// its GetInputsSum channel, which the node picks up. The node then handles
-// the request, returning the sum of the inputs in the InputsSum channel.
-// This function times out after 1 second.
-func (m *Miner) getInputSums(txs []*block.Transaction) ([]uint32, error) {
-	// time out after 1 second
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
-	// ask the node to sum the inputs for our transactions
-	m.GetInputSums <- txs
-	// wait until we get a response from the node in our SumInputs channel
-	for {
-		select {
-		case <-ctx.Done():
-			// Oops! We ran out of time
-			return []uint32{0}, fmt.Errorf("[miner.sumInputs] Error: timed out")
-		case sums := <-m.InputSums:
-			// Yay! We got a response from our node.
-			return sums, nil
-		}
-	}
-}
-
 // CalculateMintingReward calculates
 // the minting reward the miner should receive based
 // on the current chain length.
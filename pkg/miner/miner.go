@@ -3,7 +3,9 @@ package miner
 import (
 	"Coin/pkg/block"
 	"Coin/pkg/blockchain"
+	"Coin/pkg/clock"
 	"Coin/pkg/id"
+	"Coin/pkg/mempool"
 	"Coin/pkg/utils"
 	"fmt"
 	"go.uber.org/atomic"
@@ -13,21 +15,20 @@ import (
 // Miner supports the functionality of mining new transactions broadcast from the network to a new block.
 // Config represents the configuration (settings) for the miner.
 // Id represents the identity of the miner, so that the miner can properly make the coinbase transaction.
-// TxPool contains all transactions that the miner is either waiting to mine, or is mining.
+// Mempool holds the transactions the miner builds blocks from. The node sets it to its mempool.
 // MiningPool contains all transactions that the miner is currently mining.
 // ChainLength is the length of the main chain.
 // Active is a channel used to entirely shut down the miner's ability to mine.
 // Mining tells whether the miner is currently mining.
 // SendBlock is used to send newly mined blocks to the node in order to be broadcast on the network.
 // PoolUpdated is used to send alerts of pool updates to the miner
-// GetInputCoins is used by the miner to ask the node for the coins used for the inputs on
-// a block
-// InputCoins is the channel by which the node sends the requested coins back to the miner
+// Clock is used to stamp newly mined blocks. The node sets it to its network-adjusted clock.
 type Miner struct {
 	Config *Config
 	Id     id.ID
+	Clock  clock.Clock
 
-	TxPool     *TxPool
+	Mempool    *mempool.Mempool
 	MiningPool MiningPool
 
 	PreviousHash     string
@@ -41,9 +42,6 @@ type Miner struct {
 	SendBlock   chan *block.Block
 	PoolUpdated chan bool
 
-	GetInputSums chan []*block.Transaction
-	InputSums    chan []uint32
-
 	mutex sync.Mutex
 }
 
@@ -56,13 +54,11 @@ func New(c *Config, id id.ID) *Miner {
 		Config:           c,
 		PreviousHash:     blockchain.GenesisBlock(blockchain.DefaultConfig()).Hash(),
 		Id:               id,
-		TxPool:           NewTxPool(c),
-		MiningPool:       []*block.Transaction{},
+		Clock:            clock.SystemClock{},
+		MiningPool:       MiningPool{},
 		ChainLength:      atomic.NewUint32(1),
 		SendBlock:        make(chan *block.Block),
 		PoolUpdated:      make(chan bool),
-		GetInputSums:     make(chan []*block.Transaction),
-		InputSums:        make(chan []uint32),
 		Mining:           atomic.NewBool(false),
 		DifficultyTarget: c.InitialPOWDifficulty,
 		Active:           atomic.NewBool(false),
@@ -76,6 +72,20 @@ func (m *Miner) SetAddress(a string) {
 	m.mutex.Unlock()
 }
 
+// SetClock sets the clock the miner uses to stamp blocks.
+func (m *Miner) SetClock(c clock.Clock) {
+	m.mutex.Lock()
+	m.Clock = c
+	m.mutex.Unlock()
+}
+
+// SetMempool sets the mempool the miner builds blocks from.
+func (m *Miner) SetMempool(mp *mempool.Mempool) {
+	m.mutex.Lock()
+	m.Mempool = mp
+	m.mutex.Unlock()
+}
+
 // StartMiner is a wrapper around the mine method just in case any additional work is needed to do before or after
 // mining in the future.
 func (m *Miner) StartMiner() {
@@ -84,10 +94,8 @@ func (m *Miner) StartMiner() {
 	//m.PoolUpdated <- true
 }
 
-// HandleBlock handles a validated block from the network. The transactions on the block need to be checked
-// with the transaction pool, in case the transaction pool has any transactions that have already been mined.
-//The miner's perspective of the hash of the last block on the main chain needs to be reset, and the chain length
-//needs to be updated. Lastly, the miner needs to restart.
+// HandleBlock handles a validated block from the network. The node has already removed the block's
+// transactions from the mempool. The chain length needs to be updated, and the miner needs to restart.
 // Inputs:
 // b - a new block that is being added to the blockchain.
 func (m *Miner) HandleBlock(b *block.Block) {
@@ -95,27 +103,11 @@ func (m *Miner) HandleBlock(b *block.Block) {
 		return
 	}
 	m.IncrementChainLength()
-	m.UpdateTXPool(b.Transactions)
+	m.poolUpdated()
 }
 
-// UpdateTXPool handles updating
-// the transaction pool based
-// on the new transactions in the block.
-func (m *Miner) UpdateTXPool(txs []*block.Transaction) {
-	if txs == nil {
-		fmt.Printf("ERROR {Miner.HndlChkBlk}: The " +
-			"slice of inputted transactions was nil.\n")
-		return
-	}
-	//T
-	m.TxPool.CheckTransactions(txs)
-	if m.Active.Load() {
-		m.PoolUpdated <- true
-	}
-}
-
-// HandleTransaction handles a validated transaction from the network. If the miner isn't currently mining and
-// the priority threshold is met, then the miner is told to mine.
+// HandleTransaction handles a validated transaction that the node added to the mempool. If the miner
+// isn't currently mining and the priority threshold is met, then the miner is told to mine.
 // Inputs:
 // t *block.Transaction the validated transaction that was received from the network
 func (m *Miner) HandleTransaction(t *block.Transaction) {
@@ -124,13 +116,19 @@ func (m *Miner) HandleTransaction(t *block.Transaction) {
 			"inputted transaction was nil.\n")
 		return
 	}
-	sums, err := m.getInputSums([]*block.Transaction{t})
-	if err != nil {
-		utils.Debug.Printf("[miner.HandleTransaction] Failed to get inputs for transaction")
+	m.poolUpdated()
+}
+
+// poolUpdated alerts the miner that the mempool changed. The
+// alert is dropped if nothing is waiting for it, so the node
+// never blocks on the miner.
+func (m *Miner) poolUpdated() {
+	if !m.Active.Load() {
+		return
 	}
-	m.TxPool.Add(t, sums[0])
-	if m.Active.Load() {
-		m.PoolUpdated <- true
+	select {
+	case m.PoolUpdated <- true:
+	default:
 	}
 }
 
@@ -158,6 +156,12 @@ func (m *Miner) Resume() {
 	utils.Debug.Printf("%v resumed mining", utils.FmtAddr(m.Address))
 }
 
+// SetActive turns mining on or off. Unlike Pause and Resume,
+// it does not signal the mining loop, so it never blocks.
+func (m *Miner) SetActive(active bool) {
+	m.Active.Store(active)
+}
+
 // Kill closes the miner's channels and stops the current mining process.
 func (m *Miner) Kill() {
 	m.Active.Store(false)
@@ -1,24 +1,66 @@
 package miner
 
-import "Coin/pkg/block"
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/mempool"
+)
 
-// MiningPool is the list of transactions
+// coinbaseSize is the room left on a block for
+// the coinbase transaction.
+const coinbaseSize = 100
+
+// MiningPool is the list of mempool entries
 // that the miner is currently mining.
-type MiningPool []*block.Transaction
+type MiningPool []*mempool.Entry
 
-// NewMiningPool selects the highest priority
-// transactions from the transaction pool.
+// NewMiningPool selects the transactions that pay the
+// most per byte from the mempool, leaving room for
+// the coinbase.
 func (m *Miner) NewMiningPool() MiningPool {
-	var txs []*block.Transaction
-	var blkSz uint32 = 100 // assume coinbase
-	var rankings = *m.TxPool.TxQ
-	for i := 0; i < len(rankings); i++ {
-		blkSz += rankings[i].Transaction.Size()
-		if blkSz < m.Config.BlockSize {
-			txs = append(txs, rankings[i].Transaction)
-		} else {
-			break
-		}
+	if m.Mempool == nil || m.Config.BlockSize <= coinbaseSize {
+		return MiningPool{}
+	}
+	return m.Mempool.Select(m.Config.BlockSize - coinbaseSize)
+}
+
+// Transactions returns the transactions of the
+// pool, in the order a block holds them.
+func (mp MiningPool) Transactions() []*block.Transaction {
+	txs := make([]*block.Transaction, 0, len(mp))
+	for _, e := range mp {
+		txs = append(txs, e.Transaction)
 	}
 	return txs
 }
+
+// Fees returns the fees the pool's transactions
+// pay together.
+func (mp MiningPool) Fees() uint32 {
+	var fees uint64
+	for _, e := range mp {
+		fees += e.Fee
+	}
+	return uint32(fees)
+}
+
+// Priority returns the cumulative priority of
+// the pool's transactions (see CalculatePriority).
+func (mp MiningPool) Priority() uint32 {
+	var pri uint32
+	for _, e := range mp {
+		pri += CalculatePriority(e)
+	}
+	return pri
+}
+
+// CalculatePriority calculates the
+// priority of a transaction by dividing its
+// fee by its size (at least 1).
+func CalculatePriority(e *mempool.Entry) uint32 {
+	var factor uint64 = 100
+	pri := e.Fee * factor / uint64(e.Size)
+	if pri == 0 {
+		return 1
+	}
+	return uint32(pri)
+}
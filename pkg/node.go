@@ -5,15 +5,20 @@ import (
 	"Coin/pkg/address/addressdb"
 	"Coin/pkg/block"
 	"Coin/pkg/blockchain"
+	"Coin/pkg/cache"
+	"Coin/pkg/clock"
 	"Coin/pkg/id"
+	"Coin/pkg/mempool"
 	"Coin/pkg/miner"
 	"Coin/pkg/peer"
 	"Coin/pkg/pro"
+	"Coin/pkg/ratelimit"
 	"Coin/pkg/utils"
 	"Coin/pkg/wallet"
-	"errors"
+	"crypto/tls"
 	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"net"
 	"os"
 	"sync"
@@ -40,39 +45,81 @@ import (
 // Chain  *blockchain.Blockchain the blockchain
 // Wallet *wallet.Wallet the wallet
 // Mnr    *miner.Miner the miner
+// Mempool *mempool.Mempool the unconfirmed transactions, which
+// every full node keeps (nil for light clients)
+// HeaderChain *blockchain.HeaderChain the header chain, which
+// light clients keep instead of a BlockChain
+// Clock *clock.AdjustedClock the network-adjusted clock,
+// built from the times peers report in the version handshake
 // fGetAddr bool
 // AddressDb is a database of addresses
 // of nodes that it knows about in the network
 // PeerDb   peer.PeerDb a database of peers the node
 // is currently connected to
-// SeenTransactions    map[string]bool a map used to keep track
-// of whether a transaction has been seen on the network
-// before or not
-// SeenBlocks a map used to keep track
-// of whether a block has been seen on the network
-// before or not
+// SeenTransactions    *cache.Recent the hashes of the
+// transactions recently seen on the network
+// SeenBlocks *cache.Recent the hashes of the blocks
+// recently seen on the network
 // Paused bool
+// syncRequests wakes up the sync manager (see RequestSync)
+// syncMutex makes sure only one sync (see Bootstrap) runs at a time
+// chainMutex is held to change the node's chain, and held for
+// reading to read its tip (see ChainTip)
+// quit is closed when the node is killed, killOnce makes sure
+// that only happens once
+// ibd tracks initial block download (see InIBD)
+// inv tracks announced and relayed objects (see Announce)
+// compact counts how compact blocks were handled (see CompactBlockStats)
+// conn tracks outbound connections (see runConnectionManager)
+// BanList holds the addresses that may not be peers (see BanPeer)
+// Transport is how the node reaches other nodes
+// limiter and expensive limit the requests the node handles (see admit)
+// nonce is sent in the node's versions, so that the node can
+// tell when it connected to itself (see checkVersion)
+// tlsConfig secures the node's server (nil without TLS)
 type Node struct {
 	*pro.UnimplementedCoinServer
 	Server *grpc.Server
 
-	Config  *Config
-	Address string
-	Id      id.ID
+	Config    *Config
+	Address   string
+	Id        id.ID
+	Transport *address.Transport
+	tlsConfig *tls.Config
+	nonce     uint64
+	limiter   *ratelimit.Limiter
+	expensive chan struct{}
 
-	BlockChain *blockchain.BlockChain
-	Wallet     *wallet.Wallet
-	Miner      *miner.Miner
+	BlockChain  *blockchain.BlockChain
+	HeaderChain *blockchain.HeaderChain
+	Wallet      *wallet.Wallet
+	Miner       *miner.Miner
+	Mempool     *mempool.Mempool
 
-	SeenTransactions map[string]bool
-	SeenBlocks       map[string]bool
+	Clock *clock.AdjustedClock
 
-	fGetAddr bool // starts false, set to true when we request addresses from a node, cleared when we receive less than 1000 addresses from a node
+	SeenTransactions *cache.Recent
+	SeenBlocks       *cache.Recent
+
+	fGetAddr  bool // starts false, set to true when we request addresses from a node, cleared when we receive less than 1000 addresses from a node
+	addrMutex sync.Mutex
 
 	AddressDB addressdb.AddressDb
 	PeerDb    peer.PeerDb
+	BanList   *peer.BanList
 
 	Paused bool
+
+	syncRequests chan struct{}
+	syncMutex    sync.Mutex
+	chainMutex   sync.RWMutex
+	quit         chan struct{}
+	killOnce     sync.Once
+
+	ibd     ibdState
+	inv     inventory
+	compact compactStats
+	conn    connManager
 }
 
 // New returns a new Node object based on
@@ -89,31 +136,69 @@ func New(conf *Config) *Node {
 	} else {
 		n.Id, _ = id.New(n.Config.IdConfig)
 	}
-	n.BlockChain = blockchain.New(n.Config.ChainConfig)
+	n.Transport = address.NewTransport(nil)
+	if conf.TLS {
+		tlsConfig, err := address.TLSConfig(n.Id)
+		if err != nil {
+			panic(err)
+		}
+		n.tlsConfig = tlsConfig
+		n.Transport = address.NewTLSTransport(tlsConfig)
+	}
+	if conf.LightClient {
+		n.HeaderChain = blockchain.NewHeaderChain(n.Config.ChainConfig)
+	} else {
+		n.BlockChain = blockchain.New(n.Config.ChainConfig)
+		n.Mempool = mempool.New(conf.MempoolSize, conf.MempoolExpiry, conf.MaxReplacements)
+	}
 	n.Wallet = wallet.New(n.Config.WalletConfig, n.Id)
 	n.Miner = miner.New(n.Config.MinerConfig, n.Id)
-	n.SeenTransactions = make(map[string]bool)
-	n.SeenBlocks = make(map[string]bool)
-	n.AddressDB = addressdb.New(true, 1000)
-	n.PeerDb = peer.NewDb(true, 200, "")
+	n.Clock = clock.NewAdjustedClock(conf.Clock, conf.MinTimeSamples, conf.MaxTimeSamples, conf.MaxClockAdjustment)
+	if n.Miner != nil {
+		n.Miner.SetClock(n.Clock)
+		n.Miner.SetMempool(n.Mempool)
+	}
+	n.loadMempool()
+	n.SeenTransactions = cache.NewRecent(conf.SeenTransactionsSize, conf.SeenTTL)
+	n.SeenBlocks = cache.NewRecent(conf.SeenBlocksSize, conf.SeenTTL)
+	n.AddressDB = addressdb.New(conf.AddressDBPath == "", 1000, conf.AddressDBPath)
+	n.PeerDb = peer.NewDb(conf.PeerDBPath == "", conf.PeerLimit, "", conf.PeerDBPath)
+	n.BanList = peer.NewBanList(conf.BanListPath)
+	n.syncRequests = make(chan struct{}, 1)
+	n.quit = make(chan struct{})
+	n.nonce = newNonce()
+	n.conn = connManager{
+		dialing:  make(map[string]bool),
+		failures: make(map[string]int),
+		wake:     make(chan struct{}, 1),
+	}
+	n.inv = inventory{
+		requested: make(map[string]time.Time),
+		relay:     make(map[string]*block.Transaction),
+	}
 	return n
 }
 
 // BroadcastTransaction broadcasts transactions created by the wallet
-// to other peers in the network.
-func (n *Node) BroadcastTransaction(tx *block.Transaction) {
-	//TODO
-	n.Miner.HandleTransaction(tx)
-
-	n.SeenTransactions[tx.Hash()] = true // is hash the key
+// to other peers in the network. A node with a mempool only
+// broadcasts the transactions its mempool accepts, since peers
+// would reject the others.
+// Returns:
+// error why the mempool rejected the transaction (nil if it was
+// broadcast)
+func (n *Node) BroadcastTransaction(tx *block.Transaction) error {
+	if n.Mempool != nil && !n.InIBD() {
+		if err := n.acceptTransaction(tx); err != nil {
+			utils.Debug.Printf("%v did not add its own %v to the mempool: %v", utils.FmtAddr(n.Address), tx.NameTag(), err)
+			return err
+		}
+	}
 
-	//sums := uint32(0)
-	//sums <- n.Miner.InputSums    // figure out how to get inpupt sums
-	//n.Miner.TxPool.Add(tx, sums) // update tx pool
+	n.markSeen(pro.InventoryType_INVENTORY_TRANSACTION, tx.Hash())
 
-	for _, peer := range n.PeerDb.List() {
-		peer.Addr.ForwardTransactionRPC(block.EncodeTransaction(tx))
-	}
+	n.addRelayTransaction(tx)
+	n.announce(pro.InventoryType_INVENTORY_TRANSACTION, tx.Hash(), "")
+	return nil
 }
 
 // Start starts a node on the network. At first, the node is
@@ -140,7 +225,14 @@ func (n *Node) Start() {
 	if n.Config.WalletConfig.HasWallet {
 		n.Wallet.SetAddress(addr)
 	}
+	n.limiter = ratelimit.NewLimiter(n.Config.GlobalRequestRate, n.Config.GlobalRequestBurst,
+		n.Config.PeerRequestRate, n.Config.PeerRequestBurst)
+	n.expensive = make(chan struct{}, n.Config.MaxExpensiveRequests)
 	n.StartServer(addr)
+	go n.runSyncManager()
+	go n.runConnectionManager()
+	go n.runDiscovery()
+	go n.runPinger()
 	go func() {
 		if n.Config.MinerConfig.HasMiner {
 			for {
@@ -151,9 +243,6 @@ func (n *Node) Start() {
 					n.HandleMinerBlock(b)
 				case b := <-n.BlockChain.ConfirmBlock:
 					n.Wallet.HandleBlock(b.Transactions)
-				case txs := <-n.Miner.GetInputSums:
-					sums := n.BlockChain.GetInputSums(txs)
-					n.Miner.InputSums <- sums
 				}
 			}
 		} else {
@@ -174,15 +263,15 @@ func (n *Node) Start() {
 // broadcast.
 func (n *Node) HandleMinerBlock(b *block.Block) {
 	//TODO
-	n.SeenBlocks[b.Hash()] = true
+	n.markSeen(pro.InventoryType_INVENTORY_BLOCK, b.Hash())
 
-	n.BlockChain.HandleBlock(b) // update the blockchain
+	n.chainMutex.Lock()
+	n.updateMempool(n.BlockChain.HandleBlock(b)) // update the blockchain
+	n.chainMutex.Unlock()
 
 	n.Wallet.HandleBlock(b.Transactions) // wallet update its mappings
 
-	for _, peer := range n.PeerDb.List() {
-		peer.Addr.ForwardBlockRPC(block.EncodeBlock(b))
-	}
+	n.announce(pro.InventoryType_INVENTORY_BLOCK, b.Hash(), "")
 }
 
 // GetBalance returns the balance (amount of money)
@@ -194,6 +283,13 @@ func (n *Node) HandleMinerBlock(b *block.Block) {
 // uint32 the amount of money (the balance) that
 // the person with that public key has
 func (n *Node) GetBalance(pk string) uint32 {
+	if n.Config.LightClient {
+		// light clients only know about their own wallet
+		if n.Wallet != nil && pk == n.Id.GetPublicKeyString() {
+			return n.Wallet.Balance
+		}
+		return 0
+	}
 	return n.BlockChain.GetBalance(pk)
 }
 
@@ -206,33 +302,94 @@ func (n *Node) StartMiner() {
 
 // ConnectToPeer connects to a certain peer in the network. This just
 // serves as an interface for the real functionality contained
-// within the Router.
+// within the Router. Once connected, the node asks its new
+// peer for the addresses it knows (see requestAddresses).
 // Inputs:
 // addr string the address of the node that you want
 // to connect to.
 func (n *Node) ConnectToPeer(addr string) {
+	if n.BanList.IsBanned(addr) {
+		utils.Debug.Printf("%v did not connect to banned %v", utils.FmtAddr(n.Address), utils.FmtAddr(addr))
+		return
+	}
 	a := address.New(addr, 0)
-	_, err := a.VersionRPC(&pro.VersionRequest{
+	a.Transport = n.Transport
+	n.setDialing(addr, true)
+	defer n.setDialing(addr, false)
+	_, err := a.VersionRPC(n.versionRequest(addr))
+	if err != nil {
+		utils.Debug.Printf("%v could not connect to %v: %v",
+			utils.FmtAddr(n.Address), utils.FmtAddr(addr), err)
+		n.Transport.Drop(addr)
+		return
+	}
+	if n.PeerDb.Get(addr) != nil {
+		go n.requestAddresses(a)
+	} else {
+		// only peers keep a session
+		n.Transport.Drop(addr)
+	}
+}
+
+// versionRequest returns the version request this node
+// sends to the node at addrYou.
+func (n *Node) versionRequest(addrYou string) *pro.VersionRequest {
+	return &pro.VersionRequest{
 		Version:    uint32(n.Config.Version),
-		AddrYou:    addr,
+		AddrYou:    addrYou,
 		AddrMe:     n.Address,
-		BestHeight: n.BlockChain.Length,
-	})
-	if err != nil {
-		utils.Debug.Printf("%v received no response from VersionRPC to %v",
-			utils.FmtAddr(n.Address), utils.FmtAddr(addr))
+		BestHeight: n.bestHeight(),
+		Timestamp:  uint32(n.Clock.Now().Unix()),
+		Services:   n.services(),
+		Identity:   n.Id.GetPublicKeyBytes(),
+		UserAgent:  n.Config.UserAgent,
+		Nonce:      n.nonce,
+		MinVersion: uint32(n.Config.MinVersion),
+	}
+}
+
+// bestHeight returns the height of the node's best chain.
+func (n *Node) bestHeight() uint32 {
+	_, height := n.ChainTip()
+	return height
+}
+
+// ChainTip returns the hash and the height of the tip of the
+// node's best chain (the header chain on light clients). It is
+// safe to call while the node is running.
+func (n *Node) ChainTip() (string, uint32) {
+	n.chainMutex.RLock()
+	defer n.chainMutex.RUnlock()
+	if n.Config.LightClient {
+		return n.HeaderChain.LastHash, n.HeaderChain.Length
+	}
+	return n.BlockChain.LastHash, n.BlockChain.Length
+}
+
+// services returns the service flags the node advertises.
+func (n *Node) services() uint64 {
+	var services uint64
+	if n.Config.LightClient {
+		services |= peer.ServiceLight
+	} else {
+		services |= peer.ServiceFullChain
 	}
+	if n.Config.MinerConfig != nil && n.Config.MinerConfig.HasMiner {
+		services |= peer.ServiceMiner
+	}
+	return services
 }
 
 // BroadcastAddress broadcasts the node's address
 func (n *Node) BroadcastAddress() {
-	myAddr := pro.Address{Addr: n.Address, LastSeen: uint32(time.Now().UnixNano())}
+	myAddr := pro.Address{Addr: n.Address, LastSeen: uint32(time.Now().Unix())}
 	for _, p := range n.PeerDb.List() {
 		go func(addr *address.Address) {
-			_, err := addr.SendAddressesRPC(&pro.Addresses{Addrs: []*pro.Address{&myAddr}})
+			_, err := addr.SendAddressesRPC(&pro.Addresses{Addrs: []*pro.Address{&myAddr}, AddrMe: n.Address})
+			n.peerResponded(addr.Addr, err)
 			if err != nil {
 				utils.Debug.Printf("%v received no response from SendAddressesRPC to %v",
-					utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr))
+					utils.FmtAddr(n.Address), utils.FmtAddr(addr.Addr))
 			}
 		}(p.Addr)
 	}
@@ -242,41 +399,15 @@ func (n *Node) BroadcastAddress() {
 // pre-existing one that other nodes have. This may happen
 // when a node first joins the network, or if the node left
 // the network for a while (paused), then rejoined.
+// Light clients only sync headers (see SyncHeaders), and
+// full nodes do a headers-first sync (see SyncBlocks).
 func (n *Node) Bootstrap() error {
-	utils.Debug.Printf("%v bootstrapping from %v peers with top block %v", utils.FmtAddr(n.Address), len(n.PeerDb.List()), n.BlockChain.LastBlock.NameTag())
-	topBlockHash := n.BlockChain.LastHash
-	var wg sync.WaitGroup
-	var longestRes *pro.GetBlocksResponse
-	var addr *address.Address
-	if len(n.PeerDb.List()) == 0 {
-		return errors.New("no peers to bootstrap from")
-	}
-	for _, p := range n.PeerDb.List() {
-		wg.Add(1)
-		go func(p *peer.Peer) {
-			res, err := p.Addr.GetBlocksRPC(&pro.GetBlocksRequest{TopBlockHash: topBlockHash})
-			if err != nil {
-				wg.Done()
-				return
-			}
-			if longestRes == nil || len(res.BlockHashes) > len(longestRes.BlockHashes) {
-				longestRes = res
-				addr = p.Addr
-			}
-			wg.Done()
-		}(p)
-	}
-	wg.Wait()
-	if longestRes == nil {
-		return errors.New("no peers gave responses")
+	n.syncMutex.Lock()
+	defer n.syncMutex.Unlock()
+	if n.Config.LightClient {
+		return n.SyncHeaders()
 	}
-	for _, h := range longestRes.BlockHashes {
-		pb, _ := addr.GetDataRPC(&pro.GetDataRequest{BlockHash: h})
-		b := block.DecodeBlock(pb.Block)
-		n.SeenBlocks[b.Hash()] = true
-		n.BlockChain.HandleBlock(b)
-	}
-	return nil
+	return n.SyncBlocks()
 }
 
 func (n *Node) StartServer(addr string) {
@@ -285,7 +416,15 @@ func (n *Node) StartServer(addr string) {
 		panic(err)
 	}
 	// Open node to connections
-	n.Server = grpc.NewServer()
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(n.Config.MaxMessageSize),
+		grpc.MaxSendMsgSize(n.Config.MaxMessageSize),
+		grpc.UnaryInterceptor(n.limitRequests),
+	}
+	if n.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(n.tlsConfig)))
+	}
+	n.Server = grpc.NewServer(opts...)
 	pro.RegisterCoinServer(n.Server, n)
 	go func() {
 		err = n.Server.Serve(lis)
@@ -312,7 +451,20 @@ func (n *Node) ResumeNetwork() {
 }
 
 // Kill kills any threads currently managed by the Node or that
-// it previously started. It also does any necessary clean up.
+// it previously started. It also stores the mempool (see
+// saveMempool) and does any necessary clean up. A node that was
+// never started can be killed too, and killing a node that was
+// already killed does nothing.
 func (n *Node) Kill() {
-	n.Server.GracefulStop()
+	n.killOnce.Do(func() {
+		close(n.quit)
+		if n.Server != nil {
+			n.Server.GracefulStop()
+		}
+		n.Transport.Close()
+		n.saveMempool()
+		n.BanList.Close()
+		n.AddressDB.Close()
+		n.PeerDb.Close()
+	})
 }
@@ -0,0 +1,116 @@
+package peer
+
+import (
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"google.golang.org/protobuf/proto"
+	"sort"
+	"time"
+)
+
+// Ban is an address that may not be a peer.
+// Addr is the banned address.
+// Until is when the ban ends.
+// Reason is why the address was banned.
+type Ban struct {
+	Addr   string
+	Until  time.Time
+	Reason string
+}
+
+// BanList is a persistent list of banned addresses, stored
+// in a levelDB so that bans survive restarts. Expired bans
+// are removed when they are next looked at.
+type BanList struct {
+	db *leveldb.DB
+}
+
+// NewBanList returns the BanList stored at path, creating
+// it if it does not exist yet. If it can not be opened, the
+// bans are kept in memory, and do not survive a restart.
+func NewBanList(path string) *BanList {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		utils.Debug.Printf("Unable to initialize BanList with path {%v}, keeping bans in memory: %v", path, err)
+		db, _ = leveldb.Open(storage.NewMemStorage(), nil)
+	}
+	return &BanList{db: db}
+}
+
+// Ban bans an address until the given time, replacing any
+// earlier ban of the address.
+func (bl *BanList) Ban(addr string, until time.Time, reason string) {
+	bytes, err := proto.Marshal(&pro.Ban{Until: until.Unix(), Reason: reason})
+	if err != nil {
+		utils.Debug.Printf("Failed to marshal ban: %v", err)
+		return
+	}
+	if err = bl.db.Put([]byte(addr), bytes, nil); err != nil {
+		utils.Debug.Printf("Unable to store ban for address {%v}", addr)
+	}
+}
+
+// Unban lifts the ban of an address.
+// Returns:
+// bool true if the address was banned
+func (bl *BanList) Unban(addr string) bool {
+	banned := bl.IsBanned(addr)
+	if err := bl.db.Delete([]byte(addr), nil); err != nil {
+		utils.Debug.Printf("Unable to remove ban for address {%v}", addr)
+	}
+	return banned
+}
+
+// IsBanned returns whether an address is currently banned.
+func (bl *BanList) IsBanned(addr string) bool {
+	data, err := bl.db.Get([]byte(addr), nil)
+	if err != nil {
+		return false
+	}
+	return bl.decode(addr, data) != nil
+}
+
+// List returns every current ban, ordered by address.
+func (bl *BanList) List() []*Ban {
+	// expired bans are deleted while decoding, so read them all first
+	iter := bl.db.NewIterator(nil, nil)
+	var addrs, values [][]byte
+	for iter.Next() {
+		addrs = append(addrs, append([]byte{}, iter.Key()...))
+		values = append(values, append([]byte{}, iter.Value()...))
+	}
+	iter.Release()
+	var bans []*Ban
+	for i := range addrs {
+		if ban := bl.decode(string(addrs[i]), values[i]); ban != nil {
+			bans = append(bans, ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Addr < bans[j].Addr })
+	return bans
+}
+
+// Close is used to actually shut down the db (for testing purposes)
+func (bl *BanList) Close() {
+	bl.db.Close()
+}
+
+// decode returns the ban stored for an address, or nil if it
+// has expired (in which case it is removed).
+func (bl *BanList) decode(addr string, data []byte) *Ban {
+	pb := &pro.Ban{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		utils.Debug.Printf("Failed to unmarshal ban for address {%v}: %v", addr, err)
+		return nil
+	}
+	until := time.Unix(pb.Until, 0)
+	if !time.Now().Before(until) {
+		if err := bl.db.Delete([]byte(addr), nil); err != nil {
+			utils.Debug.Printf("Unable to remove expired ban for address {%v}", addr)
+		}
+		return nil
+	}
+	return &Ban{Addr: addr, Until: until, Reason: pb.Reason}
+}
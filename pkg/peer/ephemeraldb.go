@@ -3,15 +3,22 @@ package peer
 import (
 	"errors"
 	"math/rand"
+	"sync"
 )
 
+// EphemeralPeerDb is a PeerDb that keeps its peers in memory.
+// mutex guards peers, since peers are added and looked up by
+// every request the node handles.
 type EphemeralPeerDb struct {
 	peers map[string]*Peer
 	limit int
-	Addr string
+	Addr  string
+	mutex sync.RWMutex
 }
 
 func (pdb *EphemeralPeerDb) In(k string) bool {
+	pdb.mutex.RLock()
+	defer pdb.mutex.RUnlock()
 	_, in := pdb.peers[k]
 	return in
 }
@@ -22,8 +29,10 @@ func (pdb *EphemeralPeerDb) SetAddr(addr string) {
 
 // Returns true if peer existed already or was added
 func (pdb *EphemeralPeerDb) Add(p *Peer) bool {
+	pdb.mutex.Lock()
+	defer pdb.mutex.Unlock()
 	oldP := pdb.peers[p.Addr.Addr]
-	if (oldP != nil && p.Addr.LastSeen != oldP.Addr.LastSeen) || (oldP == nil && len(pdb.peers) < pdb.limit) {
+	if (oldP != nil && p.Addr.LastSeen() != oldP.Addr.LastSeen()) || (oldP == nil && len(pdb.peers) < pdb.limit) {
 		pdb.peers[p.Addr.Addr] = p
 		//utils.Debug.Printf("%v added peer %v", utils.FmtAddr(pdb.Addr), utils.FmtAddr(p.Addr.Addr))
 		return true
@@ -32,20 +41,30 @@ func (pdb *EphemeralPeerDb) Add(p *Peer) bool {
 }
 
 func (pdb *EphemeralPeerDb) Get(addr string) *Peer {
+	pdb.mutex.RLock()
+	defer pdb.mutex.RUnlock()
 	return pdb.peers[addr]
 }
 
+func (pdb *EphemeralPeerDb) Remove(addr string) {
+	pdb.mutex.Lock()
+	defer pdb.mutex.Unlock()
+	delete(pdb.peers, addr)
+}
+
 func (pdb *EphemeralPeerDb) UpdateLastSeen(addr string, lastSeen uint32) error {
-	p := pdb.peers[addr]
+	p := pdb.Get(addr)
 	if p == nil {
 		return errors.New("peer not found")
 	}
-	p.Addr.LastSeen = lastSeen
+	p.Addr.SetLastSeen(lastSeen)
 	return nil
 }
 
 // Get up to n random peers
 func (pdb *EphemeralPeerDb) GetRandom(n int, exclude []string) []*Peer {
+	pdb.mutex.RLock()
+	defer pdb.mutex.RUnlock()
 	peers := make([]*Peer, 0)
 	if n >= len(pdb.peers) {
 		for _, peer := range pdb.peers {
@@ -72,9 +91,19 @@ func (pdb *EphemeralPeerDb) GetRandom(n int, exclude []string) []*Peer {
 }
 
 func (pdb *EphemeralPeerDb) List() []*Peer {
+	pdb.mutex.RLock()
+	defer pdb.mutex.RUnlock()
 	peers := make([]*Peer, 0)
 	for _, peer := range pdb.peers {
 		peers = append(peers, peer)
 	}
 	return peers
 }
+
+// Saved returns nothing, since an EphemeralPeerDb forgets its
+// peers when the node stops.
+func (pdb *EphemeralPeerDb) Saved() []*Peer {
+	return nil
+}
+
+func (pdb *EphemeralPeerDb) Close() {}
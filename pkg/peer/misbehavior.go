@@ -0,0 +1,74 @@
+package peer
+
+import "sync/atomic"
+
+// Misbehavior is a kind of protocol violation by a peer.
+// Every kind adds its Score to the peer's misbehavior score,
+// and a peer whose score gets too high is banned.
+type Misbehavior int
+
+const (
+	// InvalidBlock is a block that fails validation.
+	InvalidBlock Misbehavior = iota
+	// InvalidHeaders are headers that do not link up, lack
+	// proof of work, or have bad timestamps.
+	InvalidHeaders
+	// InvalidTransaction is a transaction that fails validation.
+	InvalidTransaction
+	// OversizedMessage is a request or announcement with more
+	// items than the protocol allows.
+	OversizedMessage
+	// UnexpectedResponse is a response that does not match the
+	// request, such as the wrong block or too few transactions.
+	UnexpectedResponse
+	// ExceededRateLimit is a request over the peer's rate limit.
+	ExceededRateLimit
+)
+
+// misbehaviorScores maps each kind of Misbehavior to how
+// much it adds to a peer's misbehavior score.
+var misbehaviorScores = map[Misbehavior]uint32{
+	InvalidBlock:       100,
+	InvalidHeaders:     50,
+	InvalidTransaction: 10,
+	OversizedMessage:   20,
+	UnexpectedResponse: 20,
+	ExceededRateLimit:  1,
+}
+
+// Score returns how much the Misbehavior adds to a peer's
+// misbehavior score.
+func (m Misbehavior) Score() uint32 {
+	return misbehaviorScores[m]
+}
+
+func (m Misbehavior) String() string {
+	switch m {
+	case InvalidBlock:
+		return "invalid block"
+	case InvalidHeaders:
+		return "invalid headers"
+	case InvalidTransaction:
+		return "invalid transaction"
+	case OversizedMessage:
+		return "oversized message"
+	case UnexpectedResponse:
+		return "unexpected response"
+	case ExceededRateLimit:
+		return "exceeded rate limit"
+	}
+	return "unknown misbehavior"
+}
+
+// Misbehavior returns the peer's misbehavior score.
+func (p *Peer) Misbehavior() uint32 {
+	return atomic.LoadUint32(&p.misbehavior)
+}
+
+// AddMisbehavior adds a Misbehavior's score to the peer's
+// misbehavior score.
+// Returns:
+// uint32 the peer's new misbehavior score
+func (p *Peer) AddMisbehavior(m Misbehavior) uint32 {
+	return atomic.AddUint32(&p.misbehavior, m.Score())
+}
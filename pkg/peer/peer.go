@@ -2,14 +2,84 @@ package peer
 
 import (
 	"Coin/pkg/address"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Service flags are advertised in the version handshake
+// so that peers know what a node can do for them.
+// ServiceFullChain means the node stores the full chain and
+// can serve blocks, headers and merkle proofs.
+// ServiceLight means the node is a light client that only
+// keeps headers.
+// ServiceMiner means the node mines blocks.
+// ServicePruned means the node only keeps recent blocks, so
+// it can serve headers but not every block (no node prunes
+// yet, but peers may advertise it).
+const (
+	ServiceFullChain uint64 = 1 << iota
+	ServiceLight
+	ServiceMiner
+	ServicePruned
+)
+
+// Peer is a node that we are connected to.
+// Addr is the peer's address.
+// Version is the protocol version the peer speaks.
+// Services are the service flags the peer advertised.
+// Outbound is whether we connected to the peer, rather than
+// the peer to us.
+// UserAgent is the software the peer says it runs.
+// Identity is the public key of the peer's id, if the peer
+// proved that it controls the id over TLS (nil otherwise).
+// bestHeight is the height of the best chain the peer
+// has told us about (see BestHeight).
+// misbehavior is the peer's misbehavior score (see AddMisbehavior).
+// lastSeen is when the peer was last heard from, in unix
+// nanoseconds (see Seen).
+// rtt is the round-trip time of the last answered ping.
+// pingNonce and pingSent are the nonce and send time of the
+// ping we are waiting for (pingNonce is 0 if there is none),
+// guarded by pingMutex.
 type Peer struct {
-	Addr       *address.Address
-	Version    uint32
-	bestHeight uint32
+	lastSeen    int64
+	rtt         int64
+	Addr        *address.Address
+	Version     uint32
+	Services    uint64
+	Outbound    bool
+	UserAgent   string
+	Identity    []byte
+	bestHeight  uint32
+	misbehavior uint32
+	pingNonce   uint64
+	pingSent    time.Time
+	pingMutex   sync.Mutex
+}
+
+func New(addr *address.Address, version uint32, bestHeight uint32, services uint64) *Peer {
+	return &Peer{Addr: addr, Version: version, Services: services, bestHeight: bestHeight, lastSeen: time.Now().UnixNano()}
+}
+
+// HasService returns whether the peer advertised a service.
+func (p *Peer) HasService(service uint64) bool {
+	return p.Services&service != 0
+}
+
+// BestHeight returns the height of the best chain the peer
+// has told us about.
+func (p *Peer) BestHeight() uint32 {
+	return atomic.LoadUint32(&p.bestHeight)
 }
 
-func New(addr *address.Address, version uint32, bestHeight uint32) *Peer {
-	return &Peer{Addr: addr, Version: version, bestHeight: bestHeight}
+// UpdateBestHeight records that the peer has a chain of at
+// least the given height. A peer's best height never goes down.
+func (p *Peer) UpdateBestHeight(height uint32) {
+	for {
+		old := atomic.LoadUint32(&p.bestHeight)
+		if height <= old || atomic.CompareAndSwapUint32(&p.bestHeight, old, height) {
+			return
+		}
+	}
 }
@@ -3,13 +3,22 @@ package peer
 type PeerDb interface {
 	Add(*Peer) bool
 	Get(string) *Peer
+	Remove(string)
 	UpdateLastSeen(string, uint32) error
 	List() []*Peer
 	GetRandom(int, []string) []*Peer
 	In(string) bool
 	SetAddr(string)
+	Saved() []*Peer
+	Close()
 }
 
-func NewDb(eph bool, limit int, addr string) PeerDb {
+// NewDb returns a PeerDb. If eph is false, the peers are
+// also stored in a levelDB at path, so that the node can
+// reconnect to them after a restart (see Saved).
+func NewDb(eph bool, limit int, addr string, path string) PeerDb {
+	if !eph {
+		return NewPersistentDb(limit, addr, path)
+	}
 	return &EphemeralPeerDb{peers: make(map[string]*Peer), limit: limit, Addr: addr}
 }
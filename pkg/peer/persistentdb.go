@@ -0,0 +1,105 @@
+package peer
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"github.com/syndtr/goleveldb/leveldb"
+	"google.golang.org/protobuf/proto"
+)
+
+// PersistentPeerDb is a PeerDb that writes its peers through
+// to a levelDB. The peers stored by an earlier run of the node
+// are not connected, so they are only loaded as Saved peers.
+// Removed peers (such as banned ones) are deleted from the
+// levelDB as well.
+type PersistentPeerDb struct {
+	EphemeralPeerDb
+	db    *leveldb.DB
+	saved []*Peer
+}
+
+// NewPersistentDb returns a PersistentPeerDb with the peers
+// stored in the levelDB at path.
+func NewPersistentDb(limit int, addr string, path string) *PersistentPeerDb {
+	pdb := &PersistentPeerDb{
+		EphemeralPeerDb: EphemeralPeerDb{peers: make(map[string]*Peer), limit: limit, Addr: addr},
+	}
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		utils.Debug.Printf("Unable to initialize PeerDb with path {%v}", path)
+		return pdb
+	}
+	pdb.db = db
+	iter := db.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		pr := &pro.PeerRecord{}
+		if err := proto.Unmarshal(iter.Value(), pr); err != nil {
+			utils.Debug.Printf("Failed to unmarshal peer {%v}: %v", string(iter.Key()), err)
+			continue
+		}
+		a := pr.GetAddress()
+		pdb.saved = append(pdb.saved, New(address.New(a.GetAddr(), a.GetLastSeen()), pr.Version, pr.BestHeight, pr.Services))
+	}
+	return pdb
+}
+
+func (pdb *PersistentPeerDb) Add(p *Peer) bool {
+	if !pdb.EphemeralPeerDb.Add(p) {
+		return false
+	}
+	pdb.store(p)
+	return true
+}
+
+func (pdb *PersistentPeerDb) Remove(addr string) {
+	pdb.EphemeralPeerDb.Remove(addr)
+	if pdb.db == nil {
+		return
+	}
+	if err := pdb.db.Delete([]byte(addr), nil); err != nil {
+		utils.Debug.Printf("Unable to remove peer {%v}", addr)
+	}
+}
+
+func (pdb *PersistentPeerDb) UpdateLastSeen(addr string, lastSeen uint32) error {
+	if err := pdb.EphemeralPeerDb.UpdateLastSeen(addr, lastSeen); err != nil {
+		return err
+	}
+	pdb.store(pdb.Get(addr))
+	return nil
+}
+
+// Saved returns the peers that were stored by an earlier run
+// of the node.
+func (pdb *PersistentPeerDb) Saved() []*Peer {
+	return pdb.saved
+}
+
+// Close is used to actually shut down the db (for testing purposes)
+func (pdb *PersistentPeerDb) Close() {
+	if pdb.db != nil {
+		pdb.db.Close()
+	}
+}
+
+// store writes a peer to the levelDB.
+func (pdb *PersistentPeerDb) store(p *Peer) {
+	if pdb.db == nil {
+		return
+	}
+	bytes, err := proto.Marshal(&pro.PeerRecord{
+		Address:    p.Addr.Serialize(),
+		Version:    p.Version,
+		Services:   p.Services,
+		BestHeight: p.BestHeight(),
+	})
+	if err != nil {
+		utils.Debug.Printf("Failed to marshal peer: %v", err)
+		return
+	}
+	if err = pdb.db.Put([]byte(p.Addr.Addr), bytes, nil); err != nil {
+		utils.Debug.Printf("Unable to store peer {%v}", p.Addr.Addr)
+	}
+}
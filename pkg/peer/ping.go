@@ -0,0 +1,64 @@
+package peer
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Seen records that the peer was just heard from.
+func (p *Peer) Seen() {
+	atomic.StoreInt64(&p.lastSeen, time.Now().UnixNano())
+}
+
+// LastSeenTime returns when the peer was last heard from.
+func (p *Peer) LastSeenTime() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&p.lastSeen))
+}
+
+// StartPing records that a ping is being sent to the peer,
+// unless we are still waiting for the answer to another one.
+// Returns:
+// uint64 the nonce the peer has to echo in its pong
+// bool false if another ping is outstanding (no ping is started)
+func (p *Peer) StartPing() (uint64, bool) {
+	p.pingMutex.Lock()
+	defer p.pingMutex.Unlock()
+	if p.pingNonce != 0 {
+		return 0, false
+	}
+	p.pingNonce = rand.Uint64() | 1
+	p.pingSent = time.Now()
+	return p.pingNonce, true
+}
+
+// PongReceived records the peer's answer to the last ping,
+// which updates its round-trip time.
+// Returns:
+// time.Duration the round-trip time of the ping
+// bool true if nonce is that of the ping we are waiting for
+func (p *Peer) PongReceived(nonce uint64) (time.Duration, bool) {
+	p.pingMutex.Lock()
+	defer p.pingMutex.Unlock()
+	if p.pingNonce == 0 || nonce != p.pingNonce {
+		return 0, false
+	}
+	rtt := time.Since(p.pingSent)
+	p.pingNonce = 0
+	atomic.StoreInt64(&p.rtt, int64(rtt))
+	p.Seen()
+	return rtt, true
+}
+
+// CancelPing forgets the ping we are waiting for, because it failed.
+func (p *Peer) CancelPing() {
+	p.pingMutex.Lock()
+	defer p.pingMutex.Unlock()
+	p.pingNonce = 0
+}
+
+// RTT returns the round-trip time of the last answered ping
+// (0 if no ping has been answered yet).
+func (p *Peer) RTT() time.Duration {
+	return time.Duration(atomic.LoadInt64(&p.rtt))
+}
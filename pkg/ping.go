@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/utils"
+	"errors"
+	"time"
+)
+
+// errStalePong is the outcome of a ping whose pong does not
+// echo its nonce
+var errStalePong = errors.New("pong does not echo the nonce of the ping")
+
+// PeerInfo is a snapshot of what the node knows about a peer.
+// Addr is the peer's address.
+// Version is the protocol version the peer speaks.
+// Services are the service flags the peer advertised.
+// UserAgent is the software the peer says it runs.
+// Outbound is whether we connected to the peer.
+// BestHeight is the best height the peer has told us about.
+// Misbehavior is the peer's misbehavior score.
+// LastSeen is when the peer was last heard from.
+// RTT is the round-trip time of the last ping the peer
+// answered (0 if it has not answered one yet).
+type PeerInfo struct {
+	Addr        string
+	Version     uint32
+	Services    uint64
+	UserAgent   string
+	Outbound    bool
+	BestHeight  uint32
+	Misbehavior uint32
+	LastSeen    time.Time
+	RTT         time.Duration
+}
+
+// PeerInfo returns a snapshot of every peer of the node.
+func (n *Node) PeerInfo() []*PeerInfo {
+	var info []*PeerInfo
+	for _, p := range n.PeerDb.List() {
+		info = append(info, &PeerInfo{
+			Addr:        p.Addr.Addr,
+			Version:     p.Version,
+			Services:    p.Services,
+			UserAgent:   p.UserAgent,
+			Outbound:    p.Outbound,
+			BestHeight:  p.BestHeight(),
+			Misbehavior: p.Misbehavior(),
+			LastSeen:    p.LastSeenTime(),
+			RTT:         p.RTT(),
+		})
+	}
+	return info
+}
+
+// ping sends a ping to a peer, and records its round-trip
+// time if the peer echoes the nonce. A peer that is still
+// answering an earlier ping is not pinged again. A pong that
+// echoes the wrong nonce (such as a late one for an earlier
+// ping) counts as no answer. Failed pings count towards
+// dropping the peer (see peerResponded).
+func (n *Node) ping(p *peer.Peer) {
+	nonce, ok := p.StartPing()
+	if !ok {
+		return
+	}
+	res, err := p.Addr.PingRPC(&pro.PingRequest{Nonce: nonce, AddrMe: n.Address})
+	if err != nil {
+		p.CancelPing()
+		n.peerResponded(p.Addr.Addr, err)
+		utils.Debug.Printf("%v received no response from PingRPC to %v",
+			utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr))
+		return
+	}
+	rtt, ok := p.PongReceived(res.Nonce)
+	if !ok {
+		p.CancelPing()
+		n.peerResponded(p.Addr.Addr, errStalePong)
+		return
+	}
+	n.peerResponded(p.Addr.Addr, nil)
+	utils.Debug.Printf("%v pinged %v in %v", utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr), rtt)
+}
+
+// pingPeers evicts every peer that has not been heard from
+// within StaleTimeout, and pings the others.
+func (n *Node) pingPeers() {
+	for _, p := range n.PeerDb.List() {
+		if since := time.Since(p.LastSeenTime()); since > n.Config.StaleTimeout {
+			utils.Debug.Printf("%v evicted stale peer %v (last seen %v ago)",
+				utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr), since)
+			n.removePeer(p.Addr.Addr)
+			n.requestConnections()
+			continue
+		}
+		go n.ping(p)
+	}
+}
+
+// runPinger pings the node's peers every PingInterval (see
+// pingPeers), until the node is killed.
+func (n *Node) runPinger() {
+	ticker := time.NewTicker(n.Config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-n.quit:
+			return
+		}
+		n.pingPeers()
+	}
+}
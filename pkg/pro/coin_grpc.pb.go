@@ -28,12 +28,26 @@ type CoinClient interface {
 	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*Empty, error)
 	// Gets maximum 500 blocks past block with top hash
 	GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*GetBlocksResponse, error)
+	// Gets maximum 2000 headers past the first known locator hash
+	GetHeaders(ctx context.Context, in *GetHeadersRequest, opts ...grpc.CallOption) (*GetHeadersResponse, error)
 	// Get a single block
 	GetData(ctx context.Context, in *GetDataRequest, opts ...grpc.CallOption) (*GetDataResponse, error)
 	// Sends know addresses to neighbors, forwarded from node to node
 	SendAddresses(ctx context.Context, in *Addresses, opts ...grpc.CallOption) (*Empty, error)
 	// Gets neighbor addresses from node (can be multicast with static addr_me)
 	GetAddresses(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Addresses, error)
+	// Gets a merkle proof that a transaction is in a block
+	GetMerkleProof(ctx context.Context, in *GetMerkleProofRequest, opts ...grpc.CallOption) (*GetMerkleProofResponse, error)
+	// Announces transactions and blocks by hash
+	Announce(ctx context.Context, in *Inventory, opts ...grpc.CallOption) (*Empty, error)
+	// Gets announced transactions and blocks in one batch
+	GetObjects(ctx context.Context, in *GetObjectsRequest, opts ...grpc.CallOption) (*GetObjectsResponse, error)
+	// Gets the transactions of a block that were missing when rebuilding a compact block
+	GetBlockTransactions(ctx context.Context, in *GetBlockTransactionsRequest, opts ...grpc.CallOption) (*GetBlockTransactionsResponse, error)
+	// Checks that a peer is alive, and measures the round-trip time
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	// Carries all of a peer's requests (and our responses) over one connection
+	Session(ctx context.Context, opts ...grpc.CallOption) (Coin_SessionClient, error)
 }
 
 type coinClient struct {
@@ -80,6 +94,15 @@ func (c *coinClient) GetBlocks(ctx context.Context, in *GetBlocksRequest, opts .
 	return out, nil
 }
 
+func (c *coinClient) GetHeaders(ctx context.Context, in *GetHeadersRequest, opts ...grpc.CallOption) (*GetHeadersResponse, error) {
+	out := new(GetHeadersResponse)
+	err := c.cc.Invoke(ctx, "/Coin/GetHeaders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *coinClient) GetData(ctx context.Context, in *GetDataRequest, opts ...grpc.CallOption) (*GetDataResponse, error) {
 	out := new(GetDataResponse)
 	err := c.cc.Invoke(ctx, "/Coin/GetData", in, out, opts...)
@@ -107,6 +130,82 @@ func (c *coinClient) GetAddresses(ctx context.Context, in *Empty, opts ...grpc.C
 	return out, nil
 }
 
+func (c *coinClient) GetMerkleProof(ctx context.Context, in *GetMerkleProofRequest, opts ...grpc.CallOption) (*GetMerkleProofResponse, error) {
+	out := new(GetMerkleProofResponse)
+	err := c.cc.Invoke(ctx, "/Coin/GetMerkleProof", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coinClient) Announce(ctx context.Context, in *Inventory, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Coin/Announce", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coinClient) GetObjects(ctx context.Context, in *GetObjectsRequest, opts ...grpc.CallOption) (*GetObjectsResponse, error) {
+	out := new(GetObjectsResponse)
+	err := c.cc.Invoke(ctx, "/Coin/GetObjects", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coinClient) GetBlockTransactions(ctx context.Context, in *GetBlockTransactionsRequest, opts ...grpc.CallOption) (*GetBlockTransactionsResponse, error) {
+	out := new(GetBlockTransactionsResponse)
+	err := c.cc.Invoke(ctx, "/Coin/GetBlockTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coinClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, "/Coin/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coinClient) Session(ctx context.Context, opts ...grpc.CallOption) (Coin_SessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Coin_ServiceDesc.Streams[0], "/Coin/Session", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coinSessionClient{stream}
+	return x, nil
+}
+
+type Coin_SessionClient interface {
+	Send(*Envelope) error
+	Recv() (*Envelope, error)
+	grpc.ClientStream
+}
+
+type coinSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *coinSessionClient) Send(m *Envelope) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *coinSessionClient) Recv() (*Envelope, error) {
+	m := new(Envelope)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // CoinServer is the server API for Coin service.
 // All implementations must embed UnimplementedCoinServer
 // for forward compatibility
@@ -117,12 +216,26 @@ type CoinServer interface {
 	Version(context.Context, *VersionRequest) (*Empty, error)
 	// Gets maximum 500 blocks past block with top hash
 	GetBlocks(context.Context, *GetBlocksRequest) (*GetBlocksResponse, error)
+	// Gets maximum 2000 headers past the first known locator hash
+	GetHeaders(context.Context, *GetHeadersRequest) (*GetHeadersResponse, error)
 	// Get a single block
 	GetData(context.Context, *GetDataRequest) (*GetDataResponse, error)
 	// Sends know addresses to neighbors, forwarded from node to node
 	SendAddresses(context.Context, *Addresses) (*Empty, error)
 	// Gets neighbor addresses from node (can be multicast with static addr_me)
 	GetAddresses(context.Context, *Empty) (*Addresses, error)
+	// Gets a merkle proof that a transaction is in a block
+	GetMerkleProof(context.Context, *GetMerkleProofRequest) (*GetMerkleProofResponse, error)
+	// Announces transactions and blocks by hash
+	Announce(context.Context, *Inventory) (*Empty, error)
+	// Gets announced transactions and blocks in one batch
+	GetObjects(context.Context, *GetObjectsRequest) (*GetObjectsResponse, error)
+	// Gets the transactions of a block that were missing when rebuilding a compact block
+	GetBlockTransactions(context.Context, *GetBlockTransactionsRequest) (*GetBlockTransactionsResponse, error)
+	// Checks that a peer is alive, and measures the round-trip time
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	// Carries all of a peer's requests (and our responses) over one connection
+	Session(Coin_SessionServer) error
 	mustEmbedUnimplementedCoinServer()
 }
 
@@ -142,6 +255,9 @@ func (UnimplementedCoinServer) Version(context.Context, *VersionRequest) (*Empty
 func (UnimplementedCoinServer) GetBlocks(context.Context, *GetBlocksRequest) (*GetBlocksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
 }
+func (UnimplementedCoinServer) GetHeaders(context.Context, *GetHeadersRequest) (*GetHeadersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeaders not implemented")
+}
 func (UnimplementedCoinServer) GetData(context.Context, *GetDataRequest) (*GetDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetData not implemented")
 }
@@ -151,6 +267,24 @@ func (UnimplementedCoinServer) SendAddresses(context.Context, *Addresses) (*Empt
 func (UnimplementedCoinServer) GetAddresses(context.Context, *Empty) (*Addresses, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAddresses not implemented")
 }
+func (UnimplementedCoinServer) GetMerkleProof(context.Context, *GetMerkleProofRequest) (*GetMerkleProofResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMerkleProof not implemented")
+}
+func (UnimplementedCoinServer) Announce(context.Context, *Inventory) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Announce not implemented")
+}
+func (UnimplementedCoinServer) GetObjects(context.Context, *GetObjectsRequest) (*GetObjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetObjects not implemented")
+}
+func (UnimplementedCoinServer) GetBlockTransactions(context.Context, *GetBlockTransactionsRequest) (*GetBlockTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockTransactions not implemented")
+}
+func (UnimplementedCoinServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedCoinServer) Session(Coin_SessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method Session not implemented")
+}
 func (UnimplementedCoinServer) mustEmbedUnimplementedCoinServer() {}
 
 // UnsafeCoinServer may be embedded to opt out of forward compatibility for this service.
@@ -236,6 +370,24 @@ func _Coin_GetBlocks_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Coin_GetHeaders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeadersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoinServer).GetHeaders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Coin/GetHeaders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoinServer).GetHeaders(ctx, req.(*GetHeadersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Coin_GetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetDataRequest)
 	if err := dec(in); err != nil {
@@ -290,6 +442,122 @@ func _Coin_GetAddresses_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Coin_GetMerkleProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMerkleProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoinServer).GetMerkleProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Coin/GetMerkleProof",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoinServer).GetMerkleProof(ctx, req.(*GetMerkleProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Coin_Announce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Inventory)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoinServer).Announce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Coin/Announce",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoinServer).Announce(ctx, req.(*Inventory))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Coin_GetObjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetObjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoinServer).GetObjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Coin/GetObjects",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoinServer).GetObjects(ctx, req.(*GetObjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Coin_GetBlockTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoinServer).GetBlockTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Coin/GetBlockTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoinServer).GetBlockTransactions(ctx, req.(*GetBlockTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Coin_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoinServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Coin/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoinServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Coin_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CoinServer).Session(&coinSessionServer{stream})
+}
+
+type Coin_SessionServer interface {
+	Send(*Envelope) error
+	Recv() (*Envelope, error)
+	grpc.ServerStream
+}
+
+type coinSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *coinSessionServer) Send(m *Envelope) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *coinSessionServer) Recv() (*Envelope, error) {
+	m := new(Envelope)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Coin_ServiceDesc is the grpc.ServiceDesc for Coin service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -313,6 +581,10 @@ var Coin_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBlocks",
 			Handler:    _Coin_GetBlocks_Handler,
 		},
+		{
+			MethodName: "GetHeaders",
+			Handler:    _Coin_GetHeaders_Handler,
+		},
 		{
 			MethodName: "GetData",
 			Handler:    _Coin_GetData_Handler,
@@ -325,7 +597,34 @@ var Coin_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAddresses",
 			Handler:    _Coin_GetAddresses_Handler,
 		},
+		{
+			MethodName: "GetMerkleProof",
+			Handler:    _Coin_GetMerkleProof_Handler,
+		},
+		{
+			MethodName: "Announce",
+			Handler:    _Coin_Announce_Handler,
+		},
+		{
+			MethodName: "GetObjects",
+			Handler:    _Coin_GetObjects_Handler,
+		},
+		{
+			MethodName: "GetBlockTransactions",
+			Handler:    _Coin_GetBlockTransactions_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _Coin_Ping_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Session",
+			Handler:       _Coin_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "coin.proto",
 }
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Allow returns these errors for requests over a limit.
+// ErrKeyLimit means the key sent too much.
+// ErrGlobalLimit means everyone together sent too much.
+var (
+	ErrKeyLimit    = errors.New("rate limit exceeded")
+	ErrGlobalLimit = errors.New("global rate limit exceeded")
+)
+
+// maxKeys is how many keys a Limiter tracks before it forgets
+// the ones that have been idle long enough to have full buckets
+const maxKeys = 1024
+
+// Bucket is a token bucket.
+// rate is how many tokens the bucket gains per second.
+// burst is the most tokens the bucket holds.
+// tokens is how many tokens the bucket held at last.
+type Bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket returns a full Bucket.
+func NewBucket(rate float64, burst float64) *Bucket {
+	return &Bucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// refill adds the tokens gained since the bucket was last used.
+func (b *Bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+}
+
+// Take takes n tokens from the bucket, if it has that many.
+// Returns:
+// bool true if the tokens were taken
+func (b *Bucket) Take(n float64, now time.Time) bool {
+	b.refill(now)
+	if b.tokens < n {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// Limiter limits the rate of requests, both per key (such
+// as a peer) and overall.
+// global is the bucket that all requests take from.
+// keys maps keys to their buckets.
+// rate and burst are those of new key buckets.
+type Limiter struct {
+	global *Bucket
+	keys   map[string]*Bucket
+	rate   float64
+	burst  float64
+	mutex  sync.Mutex
+}
+
+// NewLimiter returns a Limiter that allows globalRate requests
+// per second overall (in bursts of up to globalBurst), and
+// keyRate per second for every key (in bursts of up to keyBurst).
+func NewLimiter(globalRate float64, globalBurst float64, keyRate float64, keyBurst float64) *Limiter {
+	return &Limiter{
+		global: NewBucket(globalRate, globalBurst),
+		keys:   make(map[string]*Bucket),
+		rate:   keyRate,
+		burst:  keyBurst,
+	}
+}
+
+// Allow takes cost tokens from the bucket of key and from the
+// global bucket.
+// Returns:
+// error ErrKeyLimit or ErrGlobalLimit if a bucket does not
+// have enough tokens (and nothing is taken), nil otherwise
+func (l *Limiter) Allow(key string, cost float64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	b := l.keys[key]
+	if b == nil {
+		if len(l.keys) >= maxKeys {
+			l.forgetIdle(now)
+		}
+		b = NewBucket(l.rate, l.burst)
+		l.keys[key] = b
+	}
+	if !b.Take(cost, now) {
+		return ErrKeyLimit
+	}
+	if !l.global.Take(cost, now) {
+		b.tokens += cost
+		return ErrGlobalLimit
+	}
+	return nil
+}
+
+// forgetIdle forgets the keys whose buckets are full again,
+// since a new bucket for them would be the same.
+func (l *Limiter) forgetIdle(now time.Time) {
+	for key, b := range l.keys {
+		if b.refill(now); b.tokens >= b.burst {
+			delete(l.keys, key)
+		}
+	}
+}
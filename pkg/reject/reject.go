@@ -0,0 +1,88 @@
+package reject
+
+import (
+	"Coin/pkg/pro"
+	"errors"
+	"fmt"
+)
+
+// Error is an error with a reject code, which tells the sender
+// of a message why it was rejected (see pro.RejectCode).
+// Its message is its reason, so it can replace plain errors.
+// Code is why the message was rejected.
+// Hash is the hash of the rejected object (empty if it has none).
+// Reason describes the rejection.
+type Error struct {
+	Code   pro.RejectCode
+	Hash   string
+	Reason string
+}
+
+// New returns an error with a reject code.
+// Inputs:
+// code pro.RejectCode why the message was rejected
+// hash string the hash of the rejected object ("" if none)
+// reason string a description of the rejection
+func New(code pro.RejectCode, hash string, reason string) *Error {
+	return &Error{Code: code, Hash: hash, Reason: reason}
+}
+
+// Errorf returns an error with a reject code, and a reason
+// formatted like fmt.Sprintf.
+func Errorf(code pro.RejectCode, hash string, format string, args ...interface{}) *Error {
+	return New(code, hash, fmt.Sprintf(format, args...))
+}
+
+func (e *Error) Error() string {
+	return e.Reason
+}
+
+// Of returns the rejection of the object with the given hash
+// that err describes. The rejection has the code of the first
+// error with a code in err's chain, or REJECT_INVALID if there
+// is none, and err's message as its reason.
+// Returns:
+// *Error the rejection (nil if err is nil)
+func Of(err error, hash string) *Error {
+	if err == nil {
+		return nil
+	}
+	code := pro.RejectCode_REJECT_INVALID
+	var e *Error
+	if errors.As(err, &e) {
+		code = e.Code
+		if hash == "" {
+			hash = e.Hash
+		}
+	}
+	return New(code, hash, err.Error())
+}
+
+// Code returns the reject code of the first error with a code
+// in err's chain.
+// Returns:
+// pro.RejectCode the code (REJECT_NONE if err is nil or has
+// no code)
+func Code(err error) pro.RejectCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return pro.RejectCode_REJECT_NONE
+}
+
+// Encode returns the reject message for err.
+// Returns:
+// *pro.Reject the message (nil if err has no reject code)
+func Encode(err error) *pro.Reject {
+	var e *Error
+	if !errors.As(err, &e) {
+		return nil
+	}
+	return &pro.Reject{Code: e.Code, Hash: e.Hash, Reason: err.Error()}
+}
+
+// Decode returns the error a reject message describes.
+func Decode(pr *pro.Reject) *Error {
+	return New(pr.Code, pr.Hash, pr.Reason)
+}
@@ -5,19 +5,42 @@ import (
 	"Coin/pkg/block"
 	"Coin/pkg/peer"
 	"Coin/pkg/pro"
+	"Coin/pkg/reject"
 	"Coin/pkg/utils"
+	"Coin/pkg/validation"
+	"bytes"
 	"errors"
 	"fmt"
 	"golang.org/x/net/context"
+	grpcpeer "google.golang.org/grpc/peer"
+	"net"
 	"time"
 )
 
-// Checks to see that requesting node is a peer and updates last seen for the peer
-func (n *Node) peerCheck(addr string) error {
-	if n.PeerDb.Get(addr) == nil {
+// MaxHeadersPerResponse is the most headers sent in reply to a GetHeaders request
+const MaxHeadersPerResponse = 2000
+
+// errLightClient is returned by light clients for requests only full nodes can serve
+var errLightClient = errors.New("light clients do not serve blocks")
+
+// errOrphanBlock is returned for blocks whose previous block we do not have
+var errOrphanBlock = reject.New(pro.RejectCode_REJECT_ORPHAN, "", "previous block is unknown")
+
+// errIdentity is returned for requests that do not come from the id they claim
+var errIdentity = reject.New(pro.RejectCode_REJECT_BAD_IDENTITY, "", "sender does not control the claimed identity")
+
+// Checks to see that requesting node is a peer (with the identity it
+// proved when it became a peer) and updates last seen for the peer
+func (n *Node) peerCheck(ctx context.Context, addr string) error {
+	p := n.PeerDb.Get(addr)
+	if p == nil {
 		return errors.New("request from non-peered node")
 	}
-	err := n.PeerDb.UpdateLastSeen(addr, uint32(time.Now().UnixNano()))
+	if _, err := n.checkSender(ctx, addr); err != nil {
+		return err
+	}
+	p.Seen()
+	err := n.PeerDb.UpdateLastSeen(addr, uint32(time.Now().Unix()))
 	if err != nil {
 		fmt.Printf("ERROR {Node.peerCheck}: error" +
 			"when calling updatelastseen.\n")
@@ -25,33 +48,131 @@ func (n *Node) peerCheck(addr string) error {
 	return nil
 }
 
+// checkSender finds out which peer a request comes from. A
+// request that claims to come from a peer that proved its
+// identity has to come from that identity; peers without one are
+// taken at their word. A request that does not say where it
+// comes from (addr is empty) comes from the peer with the
+// identity it was sent with, if there is one.
+// Returns:
+// string the address of the peer ("" if it is not from a peer)
+// error errIdentity if the request does not come from the
+// identity of the peer it claims to come from
+func (n *Node) checkSender(ctx context.Context, addr string) (string, error) {
+	if p := n.PeerDb.Get(addr); p != nil && p.Identity != nil && !bytes.Equal(address.Identity(ctx), p.Identity) {
+		return "", errIdentity
+	}
+	if from := n.authenticatedPeer(ctx, addr); from != "" {
+		return from, nil
+	}
+	if n.PeerDb.Get(addr) != nil {
+		return addr, nil
+	}
+	return "", nil
+}
+
+// authenticatedPeer returns the address of the peer a request
+// provably comes from: the peer at addr (the address the request
+// claims to come from), if it proved over TLS that it controls
+// the identity of ctx, or else any peer with that identity.
+// Peers without an identity prove nothing, so requests are not
+// attributed to them.
+// Returns:
+// string the address of the peer ("" if there is none)
+func (n *Node) authenticatedPeer(ctx context.Context, addr string) string {
+	identity := address.Identity(ctx)
+	if identity == nil {
+		return ""
+	}
+	if p := n.PeerDb.Get(addr); p != nil && bytes.Equal(p.Identity, identity) {
+		return addr
+	}
+	for _, p := range n.PeerDb.List() {
+		if bytes.Equal(p.Identity, identity) {
+			return p.Addr.Addr
+		}
+	}
+	return ""
+}
+
 // Version Handles version request (a request to become a peer)
 func (n *Node) Version(ctx context.Context, in *pro.VersionRequest) (*pro.Empty, error) {
-	// Reject all outdated versions (this is not true to Satoshi Client)
-	if int(in.Version) != n.Config.Version {
-		return &pro.Empty{}, nil
+	if err := n.checkVersion(in); err != nil {
+		utils.Debug.Printf("%v rejected version from %v: %v",
+			utils.FmtAddr(n.Address), utils.FmtAddr(in.AddrMe), err)
+		return &pro.Empty{}, err
 	}
-	// If addr map is full or does not contain addr of ver, reject
-	newAddr := address.New(in.AddrMe, uint32(time.Now().UnixNano()))
+	if n.BanList.IsBanned(in.AddrMe) {
+		return &pro.Empty{}, errBanned
+	}
+	// Over TLS the sender proved it controls the id of its certificate,
+	// which has to be the id it claims, and the id its address had so far
+	identity := address.Identity(ctx)
+	if n.Config.TLS {
+		if identity == nil || !bytes.Equal(identity, in.Identity) {
+			return &pro.Empty{}, errIdentity
+		}
+		if p := n.PeerDb.Get(in.AddrMe); p != nil && p.Identity != nil && !bytes.Equal(p.Identity, identity) {
+			return &pro.Empty{}, errIdentity
+		}
+	}
+	// Remember the address of the ver. Where it lands in the address
+	// table (or whether it fits at all) has nothing to do with
+	// whether the sender may become a peer
+	newAddr := address.New(in.AddrMe, uint32(time.Now().Unix()))
+	newAddr.Transport = n.Transport
+	var err error
 	if n.AddressDB.Get(newAddr.Addr) != nil {
-		err := n.AddressDB.UpdateLastSeen(newAddr.Addr, newAddr.LastSeen)
-		if err != nil {
-			return &pro.Empty{}, nil
+		err = n.AddressDB.UpdateLastSeen(newAddr.Addr, newAddr.LastSeen())
+	} else {
+		err = n.AddressDB.Add(newAddr, newAddr.Addr)
+	}
+	if err != nil {
+		utils.Debug.Printf("%v could not store the address of %v: %v",
+			utils.FmtAddr(n.Address), utils.FmtAddr(newAddr.Addr), err)
+	}
+	// a ver from a node we are connecting to makes it an outbound peer
+	outbound := n.isDialing(newAddr.Addr)
+	if !outbound && n.PeerDb.Get(newAddr.Addr) == nil {
+		if _, inbound := n.countPeers(); inbound >= n.Config.MaxInbound {
+			return &pro.Empty{}, errTooManyInbound
 		}
-	} else if err := n.AddressDB.Add(newAddr); err != nil {
-		return &pro.Empty{}, nil
 	}
-	newPeer := peer.New(n.AddressDB.Get(newAddr.Addr), in.Version, in.BestHeight)
+	knownAddr := n.AddressDB.Get(newAddr.Addr)
+	if knownAddr == nil || knownAddr.Transport != n.Transport {
+		// addresses loaded from disk do not use our transport
+		knownAddr = newAddr
+	}
+	newPeer := peer.New(knownAddr, in.Version, in.BestHeight, in.Services)
+	newPeer.Outbound = outbound
+	newPeer.Identity = identity
+	newPeer.UserAgent = in.UserAgent
+	if in.Timestamp != 0 {
+		n.Clock.AddSample(newAddr.Addr, time.Unix(int64(in.Timestamp), 0))
+	}
+	// Catch up if the peer has a better chain than ours
+	defer func() {
+		if in.BestHeight > n.bestHeight() {
+			n.UpdateIBD()
+			n.RequestSync()
+		}
+	}()
 	// Check if we are waiting for a ver in response to a ver, do not respond if this is a confirmation of peering
-	pendingVer := newPeer.Addr.SentVer != time.Time{} && newPeer.Addr.SentVer.Add(n.Config.VersionTimeout).After(time.Now())
-	if n.PeerDb.Add(newPeer) && !pendingVer {
-		newPeer.Addr.SentVer = time.Now()
-		_, err := newAddr.VersionRPC(&pro.VersionRequest{
-			Version:    uint32(n.Config.Version),
-			AddrYou:    in.AddrYou,
-			AddrMe:     n.Address,
-			BestHeight: n.BlockChain.Length,
-		})
+	sentVer := newPeer.Addr.SentVer()
+	pendingVer := !sentVer.IsZero() && sentVer.Add(n.Config.VersionTimeout).After(time.Now())
+	added := n.PeerDb.Add(newPeer)
+	p := n.PeerDb.Get(newAddr.Addr)
+	if p == nil {
+		return &pro.Empty{}, nil
+	}
+	bindSession(ctx, p.Addr.Addr)
+	if !added {
+		p.UpdateBestHeight(in.BestHeight)
+	}
+	// A ver from a peer that is not confirming ours means it restarted, so it needs our ver again
+	if !pendingVer {
+		newPeer.Addr.SetSentVer(time.Now())
+		_, err := newAddr.VersionRPC(n.versionRequest(in.AddrYou))
 		if err != nil {
 			return &pro.Empty{}, err
 		}
@@ -61,7 +182,15 @@ func (n *Node) Version(ctx context.Context, in *pro.VersionRequest) (*pro.Empty,
 
 // GetBlocks Handles get blocks request (request for blocks past a certain block)
 func (n *Node) GetBlocks(ctx context.Context, in *pro.GetBlocksRequest) (*pro.GetBlocksResponse, error) {
+	if _, err := n.checkSender(ctx, in.AddrMe); err != nil {
+		return &pro.GetBlocksResponse{}, err
+	}
+	if n.Config.LightClient {
+		return &pro.GetBlocksResponse{}, errLightClient
+	}
 	blockHashes := make([]string, 0)
+	n.chainMutex.RLock()
+	defer n.chainMutex.RUnlock()
 	br := n.BlockChain.BlockInfoDB.GetBlockRecord(in.TopBlockHash)
 	if br == nil {
 		return &pro.GetBlocksResponse{}, fmt.Errorf("[GetBlocks] did not have block")
@@ -79,8 +208,31 @@ func (n *Node) GetBlocks(ctx context.Context, in *pro.GetBlocksRequest) (*pro.Ge
 	return &pro.GetBlocksResponse{BlockHashes: blockHashes}, nil
 }
 
+// GetHeaders Handles get headers request (request for main chain headers past the first known locator hash)
+func (n *Node) GetHeaders(ctx context.Context, in *pro.GetHeadersRequest) (*pro.GetHeadersResponse, error) {
+	if _, err := n.checkSender(ctx, ""); err != nil {
+		return &pro.GetHeadersResponse{}, err
+	}
+	if n.Config.LightClient {
+		return &pro.GetHeadersResponse{}, errLightClient
+	}
+	var headers []*pro.Header
+	n.chainMutex.RLock()
+	defer n.chainMutex.RUnlock()
+	for _, h := range n.BlockChain.GetHeaders(in.LocatorHashes, in.StopHash, MaxHeadersPerResponse) {
+		headers = append(headers, block.EncodeHeader(h))
+	}
+	return &pro.GetHeadersResponse{Headers: headers}, nil
+}
+
 // Handles get data request (request for a specific block identified by its hash)
 func (n *Node) GetData(ctx context.Context, in *pro.GetDataRequest) (*pro.GetDataResponse, error) {
+	if _, err := n.checkSender(ctx, ""); err != nil {
+		return &pro.GetDataResponse{}, err
+	}
+	if n.Config.LightClient {
+		return &pro.GetDataResponse{}, errLightClient
+	}
 	blk := n.BlockChain.GetBlock(in.BlockHash)
 	if blk == nil {
 		utils.Debug.Printf("Node {%v} received a data req from the network for a block {%v} that could not be found locally.\n",
@@ -92,54 +244,22 @@ func (n *Node) GetData(ctx context.Context, in *pro.GetDataRequest) (*pro.GetDat
 
 // Handles send addresses request (request for nodes to peer with the requesting node)
 func (n *Node) SendAddresses(ctx context.Context, in *pro.Addresses) (*pro.Empty, error) {
-	// Forward nodes to all neighbors if new nodes were found (without redundancy)
-	foundNew := false
-	for _, addr := range in.Addrs {
-		if addr.Addr == n.Address {
-			continue
-		}
-		newAddr := address.New(addr.Addr, addr.LastSeen)
-		if p := n.PeerDb.Get(addr.Addr); p != nil {
-			if p.Addr.LastSeen < addr.LastSeen {
-				err := n.PeerDb.UpdateLastSeen(addr.Addr, addr.LastSeen)
-				if err != nil {
-					fmt.Printf("ERROR {Node.SendAddresses}: error" +
-						"when calling updatelastseen.\n")
-				}
-				foundNew = true
-			}
-		} else if a := n.AddressDB.Get(addr.Addr); a != nil {
-			if a.LastSeen < addr.LastSeen {
-				err := n.AddressDB.UpdateLastSeen(addr.Addr, addr.LastSeen)
-				if err != nil {
-					fmt.Printf("ERROR {Node.SendAddresses}: error" +
-						"when calling updatelastseen.\n")
-				}
-			}
-		} else {
-			err := n.AddressDB.Add(newAddr)
-			if err == nil {
-				foundNew = true
-			}
-		}
-		// Try to connect to each new address as true peers (it is okay if this is repeated, this may be a reboot)
-		go func() {
-			_, err := newAddr.VersionRPC(&pro.VersionRequest{
-				Version:    uint32(n.Config.Version),
-				AddrYou:    newAddr.Addr,
-				AddrMe:     n.Address,
-				BestHeight: n.BlockChain.Length,
-			})
-			if err != nil {
-				utils.Debug.Printf("%v recieved no response from VersionRPC to %v",
-					utils.FmtAddr(n.Address), utils.FmtAddr(addr.Addr))
-			}
-		}()
+	if _, err := n.checkSender(ctx, in.AddrMe); err != nil {
+		return &pro.Empty{}, err
+	}
+	if len(in.Addrs) > MaxAddresses {
+		n.misbehaving(n.authenticatedPeer(ctx, in.AddrMe), peer.OversizedMessage)
+		return &pro.Empty{}, fmt.Errorf("[SendAddresses] too many addresses: %v", len(in.Addrs))
 	}
+	// Forward nodes to all neighbors if new nodes were found (without redundancy)
+	// the addresses are filed under the host the sender connected
+	// from, since it could claim any AddrMe
+	foundNew := n.addAddresses(in.Addrs, remoteHost(ctx))
 	if foundNew {
+		relay := &pro.Addresses{Addrs: in.Addrs, AddrMe: n.Address}
 		bcPeers := n.PeerDb.GetRandom(2, []string{n.Address})
 		for _, p := range bcPeers {
-			_, err := p.Addr.SendAddressesRPC(in)
+			_, err := p.Addr.SendAddressesRPC(relay)
 			if err != nil {
 				utils.Debug.Printf("%v recieved no response from SendAddressesRPC to %v",
 					utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr))
@@ -149,71 +269,269 @@ func (n *Node) SendAddresses(ctx context.Context, in *pro.Addresses) (*pro.Empty
 	return &pro.Empty{}, nil
 }
 
+// remoteHost returns the host (without the port) that a
+// request came from, or "" if it did not come over the network.
+func remoteHost(ctx context.Context) string {
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
 // Handles get addresses request (request for all known addresses from a specific node)
 func (n *Node) GetAddresses(ctx context.Context, in *pro.Empty) (*pro.Addresses, error) {
+	if _, err := n.checkSender(ctx, ""); err != nil {
+		return &pro.Addresses{}, err
+	}
 	utils.Debug.Printf("Node {%v} received a GetAddresses req from the network.\n",
 		n.Address)
-	return &pro.Addresses{Addrs: n.AddressDB.Serialize()}, nil
+	addrs := n.AddressDB.Serialize()
+	if len(addrs) > MaxAddresses {
+		addrs = addrs[:MaxAddresses]
+	}
+	return &pro.Addresses{Addrs: addrs}, nil
 }
 
 // Handles forward transaction request (tx propagation)
 func (n *Node) ForwardTransaction(ctx context.Context, in *pro.Transaction) (*pro.Empty, error) {
-	t := block.DecodeTransaction(in)
-	_, seen := n.SeenTransactions[t.Hash()]
-	if seen {
-		return &pro.Empty{}, nil
-	} else {
-		n.SeenTransactions[t.Hash()] = true
+	from, err := n.checkSender(ctx, "")
+	if err != nil {
+		return &pro.Empty{}, err
 	}
-	if !n.CheckTransaction(t) {
-		utils.Debug.Printf("%v recieved invalid %v", utils.FmtAddr(n.Address), t.NameTag())
-		return &pro.Empty{}, errors.New("transaction is not valid")
+	return &pro.Empty{}, n.handleTransaction(block.DecodeTransaction(in), from)
+}
+
+// ForwardBlock Handles forward block request (block propagation)
+func (n *Node) ForwardBlock(ctx context.Context, in *pro.Block) (*pro.Empty, error) {
+	from, err := n.checkSender(ctx, "")
+	if err != nil {
+		return &pro.Empty{}, err
 	}
-	utils.Debug.Printf("%v recieved valid %v", utils.FmtAddr(n.Address), t.NameTag())
-	if n.Config.MinerConfig.HasMiner {
-		n.Miner.HandleTransaction(t)
+	return &pro.Empty{}, n.handleBlock(block.DecodeBlock(in), from)
+}
+
+// Announce Handles announce request (inventory of transactions and blocks a peer has)
+func (n *Node) Announce(ctx context.Context, in *pro.Inventory) (*pro.Empty, error) {
+	if err := n.peerCheck(ctx, in.AddrMe); err != nil {
+		return &pro.Empty{}, err
 	}
-	for _, p := range n.PeerDb.List() {
-		go func(addr *address.Address) {
-			_, err := addr.ForwardTransactionRPC(block.EncodeTransaction(t))
-			if err != nil {
-				utils.Debug.Printf("%v recieved no response from ForwardTransaction to %v",
-					utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr))
-			}
-		}(p.Addr)
+	if len(in.Items) > MaxInventoryItems {
+		n.misbehaving(in.AddrMe, peer.OversizedMessage)
+		return &pro.Empty{}, fmt.Errorf("[Announce] more than %v items", MaxInventoryItems)
+	}
+	var wanted []*pro.InventoryItem
+	for _, item := range in.Items {
+		if n.wantObject(item) {
+			wanted = append(wanted, item)
+		}
+	}
+	if len(wanted) > 0 {
+		go n.fetchObjects(in.AddrMe, wanted)
 	}
 	return &pro.Empty{}, nil
 }
 
-// ForwardBlock Handles forward block request (block propagation)
-func (n *Node) ForwardBlock(ctx context.Context, in *pro.Block) (*pro.Empty, error) {
-	b := block.DecodeBlock(in)
-	_, seen := n.SeenBlocks[b.Hash()]
-	if seen {
-		return &pro.Empty{}, nil
-	} else {
-		n.SeenBlocks[b.Hash()] = true
+// GetObjects Handles get objects request (batched request for announced transactions and blocks)
+func (n *Node) GetObjects(ctx context.Context, in *pro.GetObjectsRequest) (*pro.GetObjectsResponse, error) {
+	if _, err := n.checkSender(ctx, ""); err != nil {
+		return &pro.GetObjectsResponse{}, err
 	}
-	if !n.CheckBlock(b) {
-		utils.Debug.Printf("%v recieved invalid %v", utils.FmtAddr(n.Address), b.NameTag())
-		return &pro.Empty{}, errors.New("block is not valid")
+	if len(in.Items) > MaxInventoryItems {
+		return &pro.GetObjectsResponse{}, fmt.Errorf("[GetObjects] more than %v items", MaxInventoryItems)
+	}
+	res := &pro.GetObjectsResponse{}
+	for _, item := range in.Items {
+		switch item.Type {
+		case pro.InventoryType_INVENTORY_TRANSACTION:
+			if t := n.relayTransaction(item.Hash); t != nil {
+				res.Transactions = append(res.Transactions, block.EncodeTransaction(t))
+			}
+		case pro.InventoryType_INVENTORY_BLOCK:
+			if n.Config.LightClient {
+				continue
+			}
+			if b := n.BlockChain.GetBlock(item.Hash); b != nil {
+				res.Blocks = append(res.Blocks, block.EncodeBlock(b))
+			}
+		case pro.InventoryType_INVENTORY_COMPACT_BLOCK:
+			if n.Config.LightClient {
+				continue
+			}
+			if b := n.BlockChain.GetBlock(item.Hash); b != nil {
+				res.CompactBlocks = append(res.CompactBlocks, block.EncodeCompactBlock(block.NewCompactBlock(b)))
+			}
+		}
+	}
+	return res, nil
+}
+
+// GetBlockTransactions Handles get block transactions request (transactions missing when rebuilding a compact block)
+func (n *Node) GetBlockTransactions(ctx context.Context, in *pro.GetBlockTransactionsRequest) (*pro.GetBlockTransactionsResponse, error) {
+	if _, err := n.checkSender(ctx, ""); err != nil {
+		return &pro.GetBlockTransactionsResponse{}, err
+	}
+	if n.Config.LightClient {
+		return &pro.GetBlockTransactionsResponse{}, errLightClient
+	}
+	b := n.BlockChain.GetBlock(in.BlockHash)
+	if b == nil {
+		return &pro.GetBlockTransactionsResponse{}, fmt.Errorf("[GetBlockTransactions] did not have block")
+	}
+	res := &pro.GetBlockTransactionsResponse{}
+	for _, i := range in.Indexes {
+		if int(i) >= len(b.Transactions) {
+			return &pro.GetBlockTransactionsResponse{}, fmt.Errorf("[GetBlockTransactions] index %v out of range", i)
+		}
+		res.Transactions = append(res.Transactions, block.EncodeTransaction(b.Transactions[i]))
+	}
+	return res, nil
+}
+
+// handleTransaction validates a transaction from the network,
+// adds it to the mempool, and announces it to every peer except
+// the one it came from (from may be empty).
+func (n *Node) handleTransaction(t *block.Transaction, from string) error {
+	if n.Config.LightClient {
+		// light clients can't validate transactions, so they don't relay them
+		return nil
+	}
+	if n.InIBD() {
+		// we can't validate transactions until we have caught up
+		return nil
+	}
+	if !n.markSeen(pro.InventoryType_INVENTORY_TRANSACTION, t.Hash()) {
+		return reject.New(pro.RejectCode_REJECT_DUPLICATE, t.Hash(), "transaction already received")
+	}
+	if err := n.acceptTransaction(t); err != nil {
+		utils.Debug.Printf("%v recieved invalid %v: %v", utils.FmtAddr(n.Address), t.NameTag(), err)
+		// peers may relay transactions that break only our policy, or
+		// that spend coins of transactions we have not seen yet, and
+		// either may be accepted once it is relayed again later
+		if validation.IsPolicy(err) || reject.Code(err) == pro.RejectCode_REJECT_MISSING_INPUTS {
+			n.forget(pro.InventoryType_INVENTORY_TRANSACTION, t.Hash())
+		} else {
+			n.misbehaving(from, peer.InvalidTransaction)
+		}
+		return err
+	}
+	utils.Debug.Printf("%v recieved valid %v", utils.FmtAddr(n.Address), t.NameTag())
+	n.addRelayTransaction(t)
+	n.announce(pro.InventoryType_INVENTORY_TRANSACTION, t.Hash(), from)
+	return nil
+}
+
+// handleBlock validates a block from the network, adds it to
+// the chain, hands it to the miner and wallet, and announces
+// it to every peer except the one it came from (from may be
+// empty). A block that is rejected but may still be valid (an
+// orphan, a block from the future, or one on another branch)
+// is no longer marked as seen, so it is taken if it is relayed
+// again.
+func (n *Node) handleBlock(b *block.Block, from string) error {
+	if !n.markSeen(pro.InventoryType_INVENTORY_BLOCK, b.Hash()) {
+		return reject.New(pro.RejectCode_REJECT_DUPLICATE, b.Hash(), "block already received")
+	}
+	if !n.CheckHeaderTime(b.Header) {
+		// it may be valid once our clock catches up
+		n.forget(pro.InventoryType_INVENTORY_BLOCK, b.Hash())
+		return reject.New(pro.RejectCode_REJECT_BAD_TIMESTAMP, b.Hash(), "block is too far in the future")
+	}
+	if n.Config.LightClient {
+		if n.HeaderChain.GetHeader(b.Header.PreviousHash) == nil {
+			n.forget(pro.InventoryType_INVENTORY_BLOCK, b.Hash())
+			n.RequestSync()
+			return reject.Of(errOrphanBlock, b.Hash())
+		}
+		if err := n.HandleHeader(b.Header); err != nil {
+			n.misbehaving(from, peer.InvalidHeaders)
+			return err
+		}
+		return nil
+	}
+	mnChn, err := n.addBlock(b, from)
+	if err != nil {
+		return err
+	}
+	if n.InIBD() {
+		// old blocks are neither relayed nor passed to the miner and wallet
+		n.UpdateIBD()
+		return nil
 	}
-	mnChn := n.BlockChain.LastHash == b.Header.PreviousHash && n.BlockChain.CoinDB.ValidateBlock(b.Transactions)
-	n.BlockChain.HandleBlock(b)
 	if n.Config.MinerConfig.HasMiner && mnChn {
 		go n.Miner.HandleBlock(b)
 	}
 	if n.Config.WalletConfig.HasWallet && mnChn {
 		go n.Wallet.HandleBlock(b.Transactions)
 	}
-	for _, p := range n.PeerDb.List() {
-		go func(addr *address.Address) {
-			_, err := addr.ForwardBlockRPC(block.EncodeBlock(b))
-			if err != nil {
-				utils.Debug.Printf("%v recieved no response from ForwardBlockRPC to %v",
-					utils.FmtAddr(n.Address), utils.FmtAddr(p.Addr.Addr))
-			}
-		}(p.Addr)
+	n.announce(pro.InventoryType_INVENTORY_BLOCK, b.Hash(), from)
+	return nil
+}
+
+// addBlock checks a block from the network and adds it to the
+// blockchain. The chain can not change in between, since
+// chainMutex is held throughout.
+// Inputs:
+// b *block.Block the block
+// from string the address of the peer that sent the block
+// Returns:
+// bool whether the block extends the main chain
+// error if the block is an orphan or is invalid
+func (n *Node) addBlock(b *block.Block, from string) (bool, error) {
+	n.chainMutex.Lock()
+	defer n.chainMutex.Unlock()
+	if !n.BlockChain.BlockInfoDB.HasBlockRecord(b.Header.PreviousHash) {
+		// we are missing blocks, so some peer has a better chain
+		n.forget(pro.InventoryType_INVENTORY_BLOCK, b.Hash())
+		n.RequestSync()
+		return false, reject.Of(errOrphanBlock, b.Hash())
 	}
-	return &pro.Empty{}, nil
+	if err := n.CheckBlock(b); err != nil {
+		utils.Debug.Printf("%v recieved invalid %v: %v", utils.FmtAddr(n.Address), b.NameTag(), err)
+		if b.Header.PreviousHash == n.BlockChain.LastHash {
+			n.misbehaving(from, peer.InvalidBlock)
+		} else {
+			// blocks on other branches are checked against the
+			// coins of our branch, so they may well be valid
+			n.forget(pro.InventoryType_INVENTORY_BLOCK, b.Hash())
+		}
+		return false, err
+	}
+	mnChn := n.BlockChain.LastHash == b.Header.PreviousHash && n.BlockChain.CoinDB.ValidateBlock(b.Transactions)
+	n.updateMempool(n.BlockChain.HandleBlock(b))
+	return mnChn, nil
+}
+
+// GetMerkleProof Handles get merkle proof request (request for proof that a transaction is in a block)
+func (n *Node) GetMerkleProof(ctx context.Context, in *pro.GetMerkleProofRequest) (*pro.GetMerkleProofResponse, error) {
+	if _, err := n.checkSender(ctx, ""); err != nil {
+		return &pro.GetMerkleProofResponse{}, err
+	}
+	if n.Config.LightClient {
+		return &pro.GetMerkleProofResponse{}, errLightClient
+	}
+	blk := n.BlockChain.GetBlock(in.BlockHash)
+	if blk == nil {
+		return &pro.GetMerkleProofResponse{}, fmt.Errorf("[GetMerkleProof] did not have block")
+	}
+	mp, err := block.NewMerkleProof(blk.Transactions, in.TransactionHash)
+	if err != nil {
+		return &pro.GetMerkleProofResponse{}, fmt.Errorf("[GetMerkleProof] %v", err)
+	}
+	return &pro.GetMerkleProofResponse{
+		Header: block.EncodeHeader(blk.Header),
+		Proof:  block.EncodeMerkleProof(mp),
+	}, nil
+}
+
+// Ping Handles ping request (a peer checking that we are alive)
+func (n *Node) Ping(ctx context.Context, in *pro.PingRequest) (*pro.PingResponse, error) {
+	if err := n.peerCheck(ctx, in.AddrMe); err != nil {
+		return &pro.PingResponse{}, err
+	}
+	return &pro.PingResponse{Nonce: in.Nonce}, nil
 }
@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/utils"
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/protobuf/proto"
+	"sync"
+)
+
+// Session Handles a session (a stream that carries all of a
+// peer's requests, see address.MaxInFlight). Every request is
+// handled by the rpc it names, and its response is sent back
+// on the stream. The session ends when the peer closes it or
+// the node is killed.
+func (n *Node) Session(stream pro.Coin_SessionServer) error {
+	ctx := withSessionOwner(stream.Context())
+	var sendMutex sync.Mutex
+	var handlers sync.WaitGroup
+	defer handlers.Wait()
+	slots := make(chan struct{}, address.MaxInFlight)
+	requests := make(chan *pro.Envelope)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+	for {
+		var req *pro.Envelope
+		select {
+		case req = <-requests:
+		case <-errs:
+			return nil
+		case <-n.quit:
+			return nil
+		}
+		slots <- struct{}{}
+		handlers.Add(1)
+		go func() {
+			defer handlers.Done()
+			defer func() { <-slots }()
+			res := n.handleEnvelope(ctx, req)
+			sendMutex.Lock()
+			err := stream.Send(res)
+			sendMutex.Unlock()
+			if err != nil {
+				utils.Debug.Printf("%v could not respond to a %v request: %v",
+					utils.FmtAddr(n.Address), req.Method, err)
+			}
+		}()
+	}
+}
+
+// handleEnvelope handles a request that arrived on a session
+// with the rpc it names (limited like any other request, see
+// limitRequests). Errors with a reject code are sent back as
+// reject messages, so the sender learns why.
+// Returns:
+// *pro.Envelope the response to the request
+func (n *Node) handleEnvelope(ctx context.Context, req *pro.Envelope) *pro.Envelope {
+	res := &pro.Envelope{Id: req.Id}
+	for _, m := range pro.Coin_ServiceDesc.Methods {
+		if m.MethodName != req.Method {
+			continue
+		}
+		dec := func(in interface{}) error {
+			msg, ok := in.(proto.Message)
+			if !ok {
+				return errors.New("request is not a message")
+			}
+			return proto.Unmarshal(req.Payload, msg)
+		}
+		reply, err := m.Handler(n, ctx, dec, n.limitRequests)
+		if err != nil {
+			res.Error = err.Error()
+			res.Reject = reject.Encode(err)
+			return res
+		}
+		if res.Payload, err = proto.Marshal(reply.(proto.Message)); err != nil {
+			res.Error = err.Error()
+		}
+		return res
+	}
+	res.Error = fmt.Sprintf("unknown method %v", req.Method)
+	return res
+}
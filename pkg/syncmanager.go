@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"Coin/pkg/utils"
+	"errors"
+	"time"
+)
+
+// RequestSync asks the sync manager to catch up with the
+// best chain that peers have advertised. It never blocks:
+// if a sync is already pending, the request is dropped.
+func (n *Node) RequestSync() {
+	select {
+	case n.syncRequests <- struct{}{}:
+	default:
+	}
+}
+
+// BestPeerHeight returns the height of the best chain any
+// peer has told us about.
+func (n *Node) BestPeerHeight() uint32 {
+	best := uint32(0)
+	for _, p := range n.PeerDb.List() {
+		if h := p.BestHeight(); h > best {
+			best = h
+		}
+	}
+	return best
+}
+
+// CatchUp syncs the node, and keeps syncing until its best
+// chain is at least as long as the best chain its peers have
+// advertised. Each sync picks up from the node's current tip,
+// so the node catches up no matter how far behind it is.
+// Returns:
+// error if a sync failed or stopped making progress
+func (n *Node) CatchUp() error {
+	for {
+		height := n.bestHeight()
+		if err := n.Bootstrap(); err != nil {
+			return err
+		}
+		if n.BestPeerHeight() <= n.bestHeight() {
+			return nil
+		}
+		if n.bestHeight() <= height {
+			return errors.New("sync made no progress")
+		}
+	}
+}
+
+// runSyncManager catches the node up whenever a sync is
+// requested (see RequestSync), and every SyncInterval if a
+// peer has advertised a better chain, until the node is
+// killed. Only one sync runs at a time.
+func (n *Node) runSyncManager() {
+	ticker := time.NewTicker(n.Config.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.syncRequests:
+		case <-ticker.C:
+			if n.BestPeerHeight() <= n.bestHeight() {
+				continue
+			}
+		case <-n.quit:
+			return
+		}
+		n.UpdateIBD()
+		if err := n.CatchUp(); err != nil {
+			utils.Debug.Printf("%v could not catch up to height %v: %v",
+				utils.FmtAddr(n.Address), n.BestPeerHeight(), err)
+		}
+		n.UpdateIBD()
+	}
+}
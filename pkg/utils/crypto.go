@@ -35,6 +35,23 @@ func Sign(sk *ecdsa.PrivateKey, h []byte) (string, error) {
 	return hex.EncodeToString(sigB), err
 }
 
+// Verify checks a signature made by Sign.
+// Inputs:
+// pk *ecdsa.PublicKey the public key of
+// the signer
+// h []byte the hash that was signed
+// sig string the signature as a hex string
+// Returns:
+// bool	true if sig is a signature of h by
+// the private key of pk
+func Verify(pk *ecdsa.PublicKey, h []byte, sig string) bool {
+	sigB, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return ecdsa.VerifyASN1(pk, h, sigB)
+}
+
 // Byt2PK deserializes the bytes
 // to reconstruct a public key.
 // Inputs:
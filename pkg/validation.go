@@ -2,180 +2,94 @@ package pkg
 
 import (
 	"Coin/pkg/block"
-	"fmt"
+	"Coin/pkg/utils"
+	"Coin/pkg/validation"
 )
 
-// CheckBlockSyntax validates a block's
-// syntactical structure.
-// To be valid syntactically:
-// The block's first transaction must be of type Coinbase.
-// Inputs:
-// b *block.Block the block to be checked for validity
-// Returns:
-// bool True if the block is syntactically valid. false
-// otherwise
-func CheckBlockSyntax(b *block.Block) bool {
-	if b.Transactions == nil || len(b.Transactions) == 0 {
-		fmt.Printf("{Validation.ChkBlkSyn} ERROR: transactions were" +
-			" either nil or had zero length.\n")
-		return false
+// validationParams returns the settings the node checks
+// transactions and blocks against.
+func (n *Node) validationParams() *validation.Params {
+	return &validation.Params{
+		MaxBlockSize:               n.Config.MaxBlockSize,
+		POWLimit:                   n.Config.ChainConfig.POWLimit,
+		MaxFutureBlockTime:         n.Config.MaxFutureBlockTime,
+		MaxStandardTransactionSize: n.Config.MaxStandardTransactionSize,
+		MinRelayFee:                n.Config.MinRelayFee,
 	}
-	return b.Transactions[0].IsCoinbase() && b.Transactions[0].SumOutputs() > 0
 }
 
-// CheckBlockSemantics validates a block's
-// semantics.
-// To be valid semantically:
-// The merkle root of the transactions on the block must be less
-// than the difficulty target.
-// Inputs:
-// b *block.Block the block to be checked for validity
-// Returns:
-// bool True if the block is semantically valid. false
-// otherwise
-func CheckBlockSemantics(b *block.Block) bool {
-	// This function was removed so that students could implement it
-	// in miner.CalculateNonce
-	return true
+// validationContext returns what the node checks transactions
+// and blocks against: its settings, the coins of its main chain
+// and its network-adjusted time. Blocks are checked against the
+// median time past of the block with hash prevHash (if it is
+// not empty).
+func (n *Node) validationContext(prevHash string) *validation.Context {
+	c := &validation.Context{
+		Params: n.validationParams(),
+		Now:    n.Clock.Now(),
+	}
+	if n.BlockChain != nil {
+		c.Coins = n.BlockChain.CoinDB
+		if prevHash != "" {
+			c.MedianTimePast = n.BlockChain.MedianTimePast(prevHash)
+		}
+	}
+	return c
 }
 
-// CheckBlockConfiguration validates that certain
-// aspects of the block abide by the node's own configuration.
-// To be valid "configurally":
-// The block's size must be less than or equal to the largest
-// allowed block size.
+// CheckBlock validates a block against the BlockRules.
+// Its transactions are checked against the coins of the main
+// chain, so blocks on other branches may fail even if they are
+// valid.
 // Inputs:
 // b *block.Block the block to be checked for validity
 // Returns:
-// bool True if the block is configurally valid. false
-// otherwise
-func (n *Node) CheckBlockConfiguration(b *block.Block) bool {
-	return b.Size() <= n.Config.MaxBlockSize
+// error the *validation.Error of the first rule the block
+// breaks (nil if it is valid)
+func (n *Node) CheckBlock(b *block.Block) error {
+	return validation.CheckBlock(n.validationContext(b.Header.PreviousHash), b, validation.BlockRules)
 }
 
-// CheckBlock validates a block based on multiple
-// conditions.
-// To be valid:
-// The block must be syntactically (ChkBlkSyn), semantically
-// (ChkBlkSem), and configurally (ChkBlkConf) valid.
-// Each transaction on the block must be syntactically (ChkTxSyn),
-// semantically (ChkTxSem), and configurally (ChkTxConf) valid.
-// Each transaction on the block must reference UTXO on the same
-// chain (main or forked chain) and not be a double spend on that
-// chain.
+// CheckBlockTimestamp validates a block's timestamp (see
+// validation.BlockTimestamp).
 // Inputs:
 // b *block.Block the block to be checked for validity
 // Returns:
-// bool True if the block is valid. false
+// bool True if the block's timestamp is valid. false
 // otherwise
-func (n *Node) CheckBlock(b *block.Block) bool {
-	if b == nil {
-		fmt.Printf("{Validation.ChkBlk} ERROR: block was nil.\n")
+func (n *Node) CheckBlockTimestamp(b *block.Block) bool {
+	rules := []validation.BlockRule{validation.BlockTimestamp}
+	if err := validation.CheckBlock(n.validationContext(b.Header.PreviousHash), b, rules); err != nil {
+		utils.Debug.Printf("%v rejected %v: %v", utils.FmtAddr(n.Address), b.NameTag(), err)
 		return false
 	}
-	//if !(CheckBlockSyntax(b) && CheckBlockSemantics(b) && n.CheckBlockConfiguration(b)) {
-	//	return false
-	//}
-	//for i := 1; i < len(b.Transactions); i++ {
-	//	t := b.Transactions[i]
-	//	if !(CheckTransactionSyntax(t) && n.CheckTransactionSemantics(t) && n.CheckTransactionConfiguration(t)) {
-	//		return false
-	//	}
-	//}
-	return n.BlockChain.CoinDB.ValidateBlock(b.Transactions)
+	return true
 }
 
-// CheckTransactionSyntax validates a transaction
-// syntactically.
-// To be valid:
-// The transaction's inputs and outputs must not be empty.
-// The transaction's output amounts must be larger than 0.
+// CheckHeaderTime validates that a header is not from the
+// future (see validation.HeaderTime).
 // Inputs:
-// t *block.Transaction the transaction to be checked for validity
+// h *block.Header the header to be checked for validity
 // Returns:
-// bool True if the transaction is syntactically valid. false
+// bool True if the header's timestamp is valid. false
 // otherwise
-func CheckTransactionSyntax(t *block.Transaction) bool {
-	a := len(t.Inputs) > 0 && len(t.Outputs) > 0
-	for _, txo := range t.Outputs {
-		if txo.Amount <= 0 {
-			return false
-		}
+func (n *Node) CheckHeaderTime(h *block.Header) bool {
+	if err := validation.HeaderTime(n.validationContext(""), h); err != nil {
+		utils.Debug.Printf("%v rejected header %v: %v", utils.FmtAddr(n.Address), h.Hash()[:8], err)
+		return false
 	}
-	return a
-}
-
-// CheckTransactionSemantics validates a
-// a transaction semantically.
-// To be valid:
-// The sum of the transaction's inputs must be larger
-// than the sum of the transaction's outputs.
-// Inputs:
-// t *block.Transaction the transaction to be checked for validity
-// Returns:
-// bool True if the transaction is semantically valid. false
-// otherwise
-func (n *Node) CheckTransactionSemantics(tx *block.Transaction) bool {
-	return false
-}
-
-// CheckNonOrphanSemantically validates
-// a transaction semantically with the guarantee that
-// the transaction is not an orphan.
-// To be valid:
-// The transaction must not double spend any UTXO.
-// The unlocking script on each of the transaction's
-// inputs must successfully unlock each of the corresponding
-// UTXO.
-// Inputs:
-// t *block.Transaction the transaction to be checked for validity
-// Returns:
-// bool True if the transaction is semantically valid. false
-// otherwise
-func (n *Node) CheckNonOrphanSemantically(t *block.Transaction) bool {
-	//for _, inp := range t.Inputs {
-	//	if n.Chain.IsInvalidInput(inp) {
-	//		return false
-	//	}
-	//}
-	//for _, inp := range t.Inputs {
-	//	utxo := n.Chain.GetUTXO(inp)
-	//	if utxo == nil || !utxo.IsUnlckd(inp.UnlockingScript) {
-	//		return false
-	//	}
-	//}
 	return true
 }
 
-// CheckTransactionConfiguration validates
-// that certain aspects of the transaction meet the
-// required configuration settings of the node.
-// To be valid:
-// The transaction must not be larger than the
-// maximum allowed block size.
+// CheckTransaction validates a transaction that was relayed
+// on its own against the TransactionRules: the consensus rules,
+// checked against the coins of the main chain, and the node's
+// policy.
 // Inputs:
 // t *block.Transaction the transaction to be checked for validity
 // Returns:
-// bool True if the transaction is configurally valid. false
-// otherwise
-func (n *Node) CheckTransactionConfiguration(t *block.Transaction) bool {
-	return t.Size() <= n.Config.MaxBlockSize
-}
-
-// CheckTransaction validates a transaction
-// syntactically (ChkTxSyn), semantically (ChkTxSem),
-// and configurally (ChkTxConf). If the transaction
-// can be classified as an orphan, there are more
-// validity checks that have to be done (ChkNonOrfSem).
-// Inputs:
-// t *block.Transaction the transaction to be checked for validity
-// Returns:
-// bool True if the transaction is syntactically valid. false
-// otherwise
-func (n *Node) CheckTransaction(t *block.Transaction) bool {
-	valid := CheckTransactionSyntax(t) && n.CheckTransactionSemantics(t) && n.CheckTransactionConfiguration(t)
-	if err := n.BlockChain.CoinDB.ValidateTransaction(t); err != nil {
-		return false
-	}
-	return valid
+// error the *validation.Error of the first rule the transaction
+// breaks (nil if it is valid)
+func (n *Node) CheckTransaction(t *block.Transaction) error {
+	return validation.CheckTransaction(n.validationContext(""), t, validation.TransactionRules)
 }
@@ -0,0 +1,207 @@
+package validation
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HasTransactions requires a block to have transactions.
+var HasTransactions = BlockRule{
+	Name: "has-transactions",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_MALFORMED,
+	Check: func(c *Context, b *block.Block) error {
+		if len(b.Transactions) == 0 {
+			return errors.New("block has no transactions")
+		}
+		return nil
+	},
+}
+
+// Coinbase requires the first transaction of a block, and only
+// that one, to be a coinbase that mints something.
+var Coinbase = BlockRule{
+	Name: "coinbase",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_MALFORMED,
+	Check: func(c *Context, b *block.Block) error {
+		if len(b.Transactions) == 0 || !b.Transactions[0].IsCoinbase() {
+			return errors.New("first transaction is not a coinbase")
+		}
+		if sumOutputs(b.Transactions[0]) == 0 {
+			return errors.New("coinbase mints nothing")
+		}
+		for i, tx := range b.Transactions[1:] {
+			if tx.IsCoinbase() {
+				return fmt.Errorf("transaction %v is a second coinbase", i+1)
+			}
+		}
+		return nil
+	},
+}
+
+// MerkleRoot requires a block's header to commit to its
+// transactions.
+var MerkleRoot = BlockRule{
+	Name: "merkle-root",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_INVALID,
+	Check: func(c *Context, b *block.Block) error {
+		if len(b.Transactions) == 0 || b.Header.MerkleRoot != block.CalculateMerkleRoot(b.Transactions) {
+			return errors.New("merkle root does not match the transactions")
+		}
+		return nil
+	},
+}
+
+// ProofOfWork requires a block's hash to meet its difficulty
+// target, which has to be no easier than the POWLimit.
+var ProofOfWork = BlockRule{
+	Name: "proof-of-work",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_BAD_POW,
+	Check: func(c *Context, b *block.Block) error {
+		if limit := c.params().POWLimit; limit != "" {
+			return b.Header.CheckProofOfWork(limit)
+		}
+		if !b.Header.MeetsTarget() {
+			return errors.New("hash does not meet difficulty target")
+		}
+		return nil
+	},
+}
+
+// BlockSize requires a block to be no larger than the
+// MaxBlockSize.
+var BlockSize = BlockRule{
+	Name: "block-size",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_OVERSIZED,
+	Check: func(c *Context, b *block.Block) error {
+		limit := c.params().MaxBlockSize
+		if size := b.Size(); limit > 0 && size > limit {
+			return fmt.Errorf("block is %v bytes, larger than the maximum %v", size, limit)
+		}
+		return nil
+	},
+}
+
+// BlockTimestamp requires a block's timestamp to be above the
+// median time past of the block it builds on, and not to be
+// from the future (see HeaderTime).
+var BlockTimestamp = BlockRule{
+	Name: "timestamp",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_BAD_TIMESTAMP,
+	Check: func(c *Context, b *block.Block) error {
+		if b.Header.Timestamp <= c.MedianTimePast {
+			return fmt.Errorf("timestamp %v is not above the median time past %v",
+				time.Unix(int64(b.Header.Timestamp), 0), time.Unix(int64(c.MedianTimePast), 0))
+		}
+		return HeaderTime(c, b.Header)
+	},
+}
+
+// BlockTransactions requires every transaction of a block but
+// the coinbase to pass the TransactionConsensusRules. Each may
+// spend the outputs of the transactions before it, but no two
+// may spend the same coin.
+var BlockTransactions = BlockRule{
+	Name: "transactions",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_INVALID,
+	Check: func(c *Context, b *block.Block) error {
+		txContext := *c
+		var view *blockCoins
+		if c.Coins != nil {
+			view = newBlockCoins(c.Coins)
+			txContext.Coins = view
+		}
+		for i, tx := range b.Transactions {
+			if i > 0 {
+				if err := CheckTransaction(&txContext, tx, TransactionConsensusRules); err != nil {
+					return fmt.Errorf("transaction %v: %w", i, err)
+				}
+			}
+			if view != nil {
+				view.connect(tx)
+			}
+		}
+		return nil
+	},
+}
+
+// HeaderTime checks that a header is not from the future: its
+// timestamp may be at most MaxFutureBlockTime past the context's
+// current time.
+// Returns:
+// error why the timestamp is not valid, with a reject code
+// (nil if it is)
+func HeaderTime(c *Context, h *block.Header) error {
+	limit := c.params().MaxFutureBlockTime
+	if c.Now.IsZero() || limit == 0 {
+		return nil
+	}
+	ts := time.Unix(int64(h.Timestamp), 0)
+	if ts.After(c.Now.Add(limit)) {
+		return reject.Errorf(pro.RejectCode_REJECT_BAD_TIMESTAMP, h.Hash(), "timestamp %v is too far in the future", ts)
+	}
+	return nil
+}
+
+// coinLocator identifies a coin: the transaction that created
+// it and its index among that transaction's outputs.
+type coinLocator struct {
+	hash  string
+	index uint32
+}
+
+func locate(txi *block.TransactionInput) coinLocator {
+	return coinLocator{hash: txi.ReferenceTransactionHash, index: txi.OutputIndex}
+}
+
+// blockCoins are the coins transactions of a block may spend:
+// the coins of a view, as changed by the block's transactions
+// so far (see connect).
+// base is the view.
+// spent are the coins the transactions so far spent.
+// created are the outputs the transactions so far created.
+type blockCoins struct {
+	base    Coins
+	spent   map[coinLocator]bool
+	created map[coinLocator]*block.TransactionOutput
+}
+
+func newBlockCoins(base Coins) *blockCoins {
+	return &blockCoins{
+		base:    base,
+		spent:   make(map[coinLocator]bool),
+		created: make(map[coinLocator]*block.TransactionOutput),
+	}
+}
+
+func (bc *blockCoins) Coin(txi *block.TransactionInput) (*block.TransactionOutput, error) {
+	key := locate(txi)
+	if bc.spent[key] {
+		return nil, reject.New(pro.RejectCode_REJECT_DOUBLE_SPEND, "", "coin spent twice within block")
+	}
+	if txo := bc.created[key]; txo != nil {
+		return txo, nil
+	}
+	return bc.base.Coin(txi)
+}
+
+// connect spends the coins of a transaction and adds its outputs.
+func (bc *blockCoins) connect(tx *block.Transaction) {
+	for _, txi := range tx.Inputs {
+		bc.spent[locate(txi)] = true
+	}
+	hash := tx.Hash()
+	for i, txo := range tx.Outputs {
+		bc.created[coinLocator{hash: hash, index: uint32(i)}] = txo
+	}
+}
@@ -0,0 +1,227 @@
+package validation
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/pro"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// HasInputsAndOutputs requires a transaction to spend at least
+// one coin and to create at least one output.
+var HasInputsAndOutputs = TransactionRule{
+	Name: "inputs-and-outputs",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_MALFORMED,
+	Check: func(c *Context, tx *block.Transaction) error {
+		if len(tx.Inputs) == 0 {
+			return errors.New("transaction has no inputs")
+		}
+		if len(tx.Outputs) == 0 {
+			return errors.New("transaction has no outputs")
+		}
+		return nil
+	},
+}
+
+// PositiveOutputs requires every output to be worth something.
+var PositiveOutputs = TransactionRule{
+	Name: "positive-outputs",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_MALFORMED,
+	Check: func(c *Context, tx *block.Transaction) error {
+		for i, txo := range tx.Outputs {
+			if txo.Amount == 0 {
+				return fmt.Errorf("output %v is worth nothing", i)
+			}
+		}
+		return nil
+	},
+}
+
+// NoOutputOverflow requires the outputs of a transaction to be
+// worth no more than an amount can hold.
+var NoOutputOverflow = TransactionRule{
+	Name: "output-overflow",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_MALFORMED,
+	Check: func(c *Context, tx *block.Transaction) error {
+		if sumOutputs(tx) > math.MaxUint32 {
+			return errors.New("outputs are worth more than an amount can hold")
+		}
+		return nil
+	},
+}
+
+// NoDuplicateInputs requires every input of a transaction to
+// spend a different coin.
+var NoDuplicateInputs = TransactionRule{
+	Name: "duplicate-inputs",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_DOUBLE_SPEND,
+	Check: func(c *Context, tx *block.Transaction) error {
+		spent := make(map[coinLocator]bool)
+		for i, txi := range tx.Inputs {
+			key := locate(txi)
+			if spent[key] {
+				return fmt.Errorf("input %v spends a coin an earlier input spends", i)
+			}
+			spent[key] = true
+		}
+		return nil
+	},
+}
+
+// TransactionSize requires a transaction to fit in a block.
+var TransactionSize = TransactionRule{
+	Name: "transaction-size",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_OVERSIZED,
+	Check: func(c *Context, tx *block.Transaction) error {
+		limit := c.params().MaxBlockSize
+		if size := tx.Size(); limit > 0 && size > limit {
+			return fmt.Errorf("transaction is %v bytes, larger than the maximum block size %v", size, limit)
+		}
+		return nil
+	},
+}
+
+// InputsExist requires every input of a transaction to spend
+// an unspent coin.
+var InputsExist = TransactionRule{
+	Name: "inputs-exist",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_MISSING_INPUTS,
+	Check: func(c *Context, tx *block.Transaction) error {
+		if c.Coins == nil {
+			return nil
+		}
+		for _, txi := range tx.Inputs {
+			if _, err := c.Coins.Coin(txi); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// InputsCoverOutputs requires the coins a transaction spends
+// to be worth at least as much as its outputs.
+var InputsCoverOutputs = TransactionRule{
+	Name: "inputs-cover-outputs",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_INSUFFICIENT_FEE,
+	Check: func(c *Context, tx *block.Transaction) error {
+		if c.Coins == nil {
+			return nil
+		}
+		in, err := sumInputs(c.Coins, tx)
+		if err != nil {
+			return err
+		}
+		if out := sumOutputs(tx); in < out {
+			return fmt.Errorf("outputs are worth %v, more than the inputs' %v", out, in)
+		}
+		return nil
+	},
+}
+
+// ValidSignatures requires the unlocking script of every input
+// to be a signature of the transaction by the owner of the coin
+// it spends (see block.TransactionOutput.CheckSignature).
+var ValidSignatures = TransactionRule{
+	Name: "signatures",
+	Kind: Consensus,
+	Code: pro.RejectCode_REJECT_INVALID_SIGNATURE,
+	Check: func(c *Context, tx *block.Transaction) error {
+		if c.Coins == nil {
+			return nil
+		}
+		for i, txi := range tx.Inputs {
+			txo, err := c.Coins.Coin(txi)
+			if err != nil {
+				return err
+			}
+			if err := txo.CheckSignature(tx, txi.UnlockingScript); err != nil {
+				return fmt.Errorf("input %v: %v", i, err)
+			}
+		}
+		return nil
+	},
+}
+
+// StandardSize keeps large transactions, which are expensive to
+// relay, out of the node's pool (policy).
+var StandardSize = TransactionRule{
+	Name: "standard-size",
+	Kind: Policy,
+	Code: pro.RejectCode_REJECT_OVERSIZED,
+	Check: func(c *Context, tx *block.Transaction) error {
+		limit := c.params().MaxStandardTransactionSize
+		if size := tx.Size(); limit > 0 && size > limit {
+			return fmt.Errorf("transaction is %v bytes, larger than the standard %v", size, limit)
+		}
+		return nil
+	},
+}
+
+// MinRelayFee keeps transactions that pay too little to be
+// worth mining out of the node's pool (policy).
+var MinRelayFee = TransactionRule{
+	Name: "min-relay-fee",
+	Kind: Policy,
+	Code: pro.RejectCode_REJECT_INSUFFICIENT_FEE,
+	Check: func(c *Context, tx *block.Transaction) error {
+		if c.Coins == nil {
+			return nil
+		}
+		fee, err := Fee(c.Coins, tx)
+		if err != nil {
+			return err
+		}
+		if min := c.params().MinRelayFee; fee < uint64(min) {
+			return fmt.Errorf("fee %v is below the minimum relay fee %v", fee, min)
+		}
+		return nil
+	},
+}
+
+// Fee returns the fee a transaction pays: what its inputs are
+// worth beyond its outputs.
+// Returns:
+// uint64 the fee (0 if the outputs are worth more than the inputs)
+// error if a coin the transaction spends can not be found
+func Fee(coins Coins, tx *block.Transaction) (uint64, error) {
+	in, err := sumInputs(coins, tx)
+	if err != nil {
+		return 0, err
+	}
+	if out := sumOutputs(tx); in > out {
+		return in - out, nil
+	}
+	return 0, nil
+}
+
+// sumInputs returns what the coins a transaction spends are worth.
+func sumInputs(coins Coins, tx *block.Transaction) (uint64, error) {
+	var sum uint64
+	for _, txi := range tx.Inputs {
+		txo, err := coins.Coin(txi)
+		if err != nil {
+			return 0, err
+		}
+		sum += uint64(txo.Amount)
+	}
+	return sum, nil
+}
+
+// sumOutputs returns what the outputs of a transaction are
+// worth (without overflowing, unlike block.Transaction.SumOutputs).
+func sumOutputs(tx *block.Transaction) uint64 {
+	var sum uint64
+	for _, txo := range tx.Outputs {
+		sum += uint64(txo.Amount)
+	}
+	return sum
+}
@@ -0,0 +1,214 @@
+package validation
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kind is whether a rule is a consensus rule or a policy rule.
+// Every node has to agree on consensus rules, which decide
+// whether a transaction or block is valid at all. Policy rules
+// are local: a node uses them to decide which valid transactions
+// it relays and mines, so breaking them is not misbehavior.
+type Kind int
+
+const (
+	Consensus Kind = iota
+	Policy
+)
+
+func (k Kind) String() string {
+	if k == Policy {
+		return "policy"
+	}
+	return "consensus"
+}
+
+// Error is a broken rule. It wraps the rejection of the
+// object that broke it, so reject.Code finds its code.
+// Rule is the name of the rule.
+// Kind is whether the rule is a consensus or a policy rule.
+// Reject is the rejection of the object.
+type Error struct {
+	Rule   string
+	Kind   Kind
+	Reject *reject.Error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v rule %v: %v", e.Kind, e.Rule, e.Reject.Reason)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Reject
+}
+
+// IsPolicy returns whether err is a broken policy rule (so the
+// object may well be valid).
+func IsPolicy(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == Policy
+}
+
+// Params are the settings that rules check against. Rules skip
+// the checks whose setting is zero.
+// MaxBlockSize is the size of the largest valid block, and so
+// of the largest valid transaction.
+// POWLimit is the easiest difficulty target a block may have
+// (if it is empty, blocks only have to meet their own target).
+// MaxFutureBlockTime is how far past the current time a block's
+// timestamp may be.
+// MaxStandardTransactionSize is the size of the largest
+// transaction that is relayed (policy).
+// MinRelayFee is the smallest fee of a transaction that is
+// relayed (policy).
+type Params struct {
+	MaxBlockSize       uint32
+	POWLimit           string
+	MaxFutureBlockTime time.Duration
+
+	MaxStandardTransactionSize uint32
+	MinRelayFee                uint32
+}
+
+// DefaultParams returns the default settings for rules.
+func DefaultParams() *Params {
+	return &Params{
+		MaxBlockSize:               10000000,
+		MaxFutureBlockTime:         time.Hour * 2,
+		MaxStandardTransactionSize: 100000,
+		MinRelayFee:                1,
+	}
+}
+
+// Coins looks up the coins that transactions spend.
+type Coins interface {
+	// Coin returns the unspent output that an input spends, or
+	// an error (with a reject code) if there is none.
+	Coin(txi *block.TransactionInput) (*block.TransactionOutput, error)
+}
+
+// Context is what rules check objects against. Rules skip the
+// checks that need something the context does not have, so an
+// empty Context only runs the checks that need nothing but the
+// object itself.
+// Params are the settings (nil means no settings).
+// Coins are the coins transactions may spend.
+// Now is the current (network-adjusted) time.
+// MedianTimePast is the median time past of the block a block
+// builds on (without it, a block only has to be stamped after
+// the epoch).
+type Context struct {
+	Params         *Params
+	Coins          Coins
+	Now            time.Time
+	MedianTimePast uint32
+}
+
+// params returns the context's settings.
+func (c *Context) params() *Params {
+	if c.Params == nil {
+		return &Params{}
+	}
+	return c.Params
+}
+
+// TransactionRule is a named check on a transaction.
+// Name identifies the rule in errors.
+// Kind is whether it is a consensus or a policy rule.
+// Code is the reject code of transactions that break it,
+// unless Check's error has a code of its own.
+// Check returns why a transaction breaks the rule (nil if it
+// does not).
+type TransactionRule struct {
+	Name  string
+	Kind  Kind
+	Code  pro.RejectCode
+	Check func(c *Context, tx *block.Transaction) error
+}
+
+// BlockRule is a named check on a block (see TransactionRule).
+type BlockRule struct {
+	Name  string
+	Kind  Kind
+	Code  pro.RejectCode
+	Check func(c *Context, b *block.Block) error
+}
+
+// broken returns the error for an object with the given hash
+// that broke a rule.
+func broken(name string, kind Kind, code pro.RejectCode, hash string, err error) *Error {
+	if reject.Code(err) != pro.RejectCode_REJECT_NONE {
+		code = reject.Code(err)
+	}
+	return &Error{Rule: name, Kind: kind, Reject: reject.New(code, hash, err.Error())}
+}
+
+// CheckTransaction checks a transaction against rules, in order.
+// Returns:
+// error the *Error of the first rule the transaction breaks
+// (nil if it breaks none)
+func CheckTransaction(c *Context, tx *block.Transaction, rules []TransactionRule) error {
+	for _, r := range rules {
+		if err := r.Check(c, tx); err != nil {
+			return broken(r.Name, r.Kind, r.Code, tx.Hash(), err)
+		}
+	}
+	return nil
+}
+
+// CheckBlock checks a block against rules, in order.
+// Returns:
+// error the *Error of the first rule the block breaks (nil
+// if it breaks none)
+func CheckBlock(c *Context, b *block.Block, rules []BlockRule) error {
+	for _, r := range rules {
+		if err := r.Check(c, b); err != nil {
+			return broken(r.Name, r.Kind, r.Code, b.Hash(), err)
+		}
+	}
+	return nil
+}
+
+// TransactionConsensusRules are the rules every transaction
+// that is not a coinbase has to pass, in the order they are
+// checked: first the ones that only need the transaction,
+// then the ones that need its coins, and the signatures (which
+// are the most expensive to check) last.
+var TransactionConsensusRules = []TransactionRule{
+	HasInputsAndOutputs,
+	PositiveOutputs,
+	NoOutputOverflow,
+	NoDuplicateInputs,
+	TransactionSize,
+	InputsExist,
+	InputsCoverOutputs,
+	ValidSignatures,
+}
+
+// TransactionPolicyRules are the rules a node applies to the
+// valid transactions it relays.
+var TransactionPolicyRules = []TransactionRule{
+	StandardSize,
+	MinRelayFee,
+}
+
+// TransactionRules are the rules a transaction that is relayed
+// on its own has to pass: consensus rules, then policy rules.
+var TransactionRules = append(append([]TransactionRule{}, TransactionConsensusRules...), TransactionPolicyRules...)
+
+// BlockRules are the rules every block has to pass, in the
+// order they are checked.
+var BlockRules = []BlockRule{
+	HasTransactions,
+	Coinbase,
+	MerkleRoot,
+	ProofOfWork,
+	BlockSize,
+	BlockTimestamp,
+	BlockTransactions,
+}
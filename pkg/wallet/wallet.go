@@ -4,6 +4,12 @@ import (
 	"Coin/pkg/block"
 	"Coin/pkg/blockchain/chainwriter"
 	"Coin/pkg/id"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/utils"
+	"Coin/pkg/validation"
+	"encoding/hex"
+	"sync"
 )
 
 // CoinInfo holds the information about a TransactionOutput
@@ -19,6 +25,17 @@ type CoinInfo struct {
 	TransactionOutput        *block.TransactionOutput
 }
 
+// PendingTransaction is a payment the wallet made that is not
+// on the chain yet, so it can still be replaced (see BumpFee).
+// Transaction is the payment.
+// Coins are the coins it spends.
+// Fee is the fee it pays.
+type PendingTransaction struct {
+	Transaction *block.Transaction
+	Coins       []*CoinInfo
+	Fee         uint32
+}
+
 // Wallet handles keeping track of the owner's coins
 //
 // # CoinCollection is the owner of this wallet's set of coins
@@ -34,6 +51,9 @@ type CoinInfo struct {
 // UnconfirmedReceivedCoins is a mapping of CoinInfos to number of confirmations
 // (which are integers). We can't confirm we've received a Coin until
 // we've seen enough POW on top the block containing our received transaction.
+//
+// Pending maps the hashes of the payments we made that are not on the chain
+// yet to the payments. pendingMutex protects it.
 type Wallet struct {
 	Config              *Config
 	Id                  id.ID
@@ -50,6 +70,9 @@ type Wallet struct {
 	// Seen but not confirmed
 	UnconfirmedSpentCoins    map[*CoinInfo]uint32
 	UnconfirmedReceivedCoins map[*CoinInfo]uint32
+
+	Pending      map[string]*PendingTransaction
+	pendingMutex sync.Mutex
 }
 
 // SetAddress sets the address
@@ -72,6 +95,7 @@ func New(config *Config, id id.ID) *Wallet {
 		UnseenSpentCoins:         make(map[string][]*CoinInfo),
 		UnconfirmedSpentCoins:    make(map[*CoinInfo]uint32),
 		UnconfirmedReceivedCoins: make(map[*CoinInfo]uint32),
+		Pending:                  make(map[string]*PendingTransaction),
 	}
 }
 
@@ -87,8 +111,7 @@ func (w *Wallet) generateTransactionInputs(amount uint32, fee uint32) (uint32, [
 	for sum < amount+fee {
 		for _, coin := range w.CoinCollection {
 			sum += coin.TransactionOutput.Amount
-			signature, _ := coin.TransactionOutput.MakeSignature(w.Id)
-			inp := &block.TransactionInput{coin.ReferenceTransactionHash, coin.OutputIndex, signature}
+			inp := &block.TransactionInput{ReferenceTransactionHash: coin.ReferenceTransactionHash, OutputIndex: coin.OutputIndex}
 			inputs = append(inputs, inp)
 			coins = append(coins, coin)
 			if sum >= amount+fee {
@@ -111,10 +134,10 @@ func (w *Wallet) generateTransactionOutputs(
 ) []*block.TransactionOutput {
 	//TODO: optional, but we recommend using a helper like this
 	var outputs []*block.TransactionOutput
-	output_receiver := &block.TransactionOutput{amount, string(receiverPK)}
+	output_receiver := &block.TransactionOutput{Amount: amount, LockingScript: hex.EncodeToString(receiverPK)}
 	outputs = append(outputs, output_receiver)
 	if change > 0 {
-		output_change := &block.TransactionOutput{change, w.Id.GetPublicKeyString()}
+		output_change := &block.TransactionOutput{Amount: change, LockingScript: w.Id.GetPublicKeyString()}
 		outputs = append(outputs, output_change)
 	}
 	return outputs
@@ -129,17 +152,146 @@ func (w *Wallet) RequestTransaction(amount uint32, fee uint32, recipientPK []byt
 	}
 	change, inputs, coins := w.generateTransactionInputs(amount, fee)
 	outputs := w.generateTransactionOutputs(amount, recipientPK, change)
+	transac := &block.Transaction{Version: 0, Inputs: inputs, Outputs: outputs, LockTime: 0}
+	w.sign(transac, coins)
+	// nodes would not relay a transaction that breaks their rules
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: spendable(coins)}
+	if err := validation.CheckTransaction(c, transac, validation.TransactionRules); err != nil {
+		utils.Debug.Printf("[wallet.RequestTransaction] %v", err)
+		return nil
+	}
 
 	for _, coin := range coins {
 		delete(w.CoinCollection, coin.TransactionOutput)
 		w.UnseenSpentCoins[coin.ReferenceTransactionHash] = append(w.UnseenSpentCoins[coin.ReferenceTransactionHash], coin)
 	}
-	transac := &block.Transaction{Version: 0, Inputs: inputs, Outputs: outputs, LockTime: 0}
 
 	w.Balance -= change + fee + amount
+	w.pendingMutex.Lock()
+	w.Pending[transac.Hash()] = &PendingTransaction{Transaction: transac, Coins: coins, Fee: fee}
+	w.pendingMutex.Unlock()
 	return transac
 }
 
+// BumpFee replaces a pending payment with one that spends the
+// same coins and pays the same amount, but pays a higher fee out
+// of the change. Nodes accept the replacement in place of the
+// payment (see mempool.Mempool.Add), and once the wallet's node
+// does, the replacement is pending instead of the payment (see
+// HandleReplacement).
+// Inputs:
+// hash string the hash of the pending payment
+// fee uint32 the new fee, which has to be higher than the old one
+// Returns:
+// *block.Transaction the replacement (nil if the payment is not
+// pending, or its change can not cover the higher fee)
+func (w *Wallet) BumpFee(hash string, fee uint32) *block.Transaction {
+	w.pendingMutex.Lock()
+	defer w.pendingMutex.Unlock()
+	p := w.Pending[hash]
+	if p == nil || fee <= p.Fee {
+		return nil
+	}
+	extra := fee - p.Fee
+	outputs := p.Transaction.Outputs
+	// the change is the last output, after the payment
+	last := len(outputs) - 1
+	if last < 1 || outputs[last].LockingScript != w.Id.GetPublicKeyString() || outputs[last].Amount < extra {
+		utils.Debug.Printf("[wallet.BumpFee] not enough change to pay a fee of %v", fee)
+		return nil
+	}
+	change := outputs[last].Amount - extra
+	outputs = append([]*block.TransactionOutput{}, outputs[:last]...)
+	if change > 0 {
+		outputs = append(outputs, &block.TransactionOutput{Amount: change, LockingScript: w.Id.GetPublicKeyString()})
+	}
+	// the payment's signatures do not cover the new outputs
+	var inputs []*block.TransactionInput
+	for _, txi := range p.Transaction.Inputs {
+		inputs = append(inputs, &block.TransactionInput{ReferenceTransactionHash: txi.ReferenceTransactionHash, OutputIndex: txi.OutputIndex})
+	}
+	replacement := &block.Transaction{
+		Version:  p.Transaction.Version,
+		Inputs:   inputs,
+		Outputs:  outputs,
+		LockTime: p.Transaction.LockTime,
+	}
+	w.sign(replacement, p.Coins)
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: spendable(p.Coins)}
+	if err := validation.CheckTransaction(c, replacement, validation.TransactionRules); err != nil {
+		utils.Debug.Printf("[wallet.BumpFee] %v", err)
+		return nil
+	}
+	return replacement
+}
+
+// HandleReplacement handles a transaction that replaced another
+// one in the node's mempool. If the replaced one was a pending
+// payment, it is no longer pending (and can not be bumped). A
+// replacement that only spends the payment's coins (such as one
+// made by BumpFee) is pending in its place.
+func (w *Wallet) HandleReplacement(replaced, replacement *block.Transaction) {
+	w.pendingMutex.Lock()
+	defer w.pendingMutex.Unlock()
+	hash := replaced.Hash()
+	p := w.Pending[hash]
+	if p == nil {
+		return
+	}
+	delete(w.Pending, hash)
+	if fee, err := validation.Fee(spendable(p.Coins), replacement); err == nil {
+		w.Pending[replacement.Hash()] = &PendingTransaction{Transaction: replacement, Coins: p.Coins, Fee: uint32(fee)}
+		return
+	}
+	utils.Debug.Printf("%v payment %v was replaced by %v",
+		utils.FmtAddr(w.Address), replaced.NameTag(), replacement.NameTag())
+}
+
+// confirmPending stops tracking the pending payments that spend
+// the same coins as a transaction on the chain: either it is the
+// payment (or a replacement of it), or the payment can never be
+// valid.
+func (w *Wallet) confirmPending(tx *block.Transaction) {
+	w.pendingMutex.Lock()
+	defer w.pendingMutex.Unlock()
+	spent := make(map[CoinInfo]bool)
+	for _, txi := range tx.Inputs {
+		spent[CoinInfo{ReferenceTransactionHash: txi.ReferenceTransactionHash, OutputIndex: txi.OutputIndex}] = true
+	}
+	for hash, p := range w.Pending {
+		for _, txi := range p.Transaction.Inputs {
+			if spent[CoinInfo{ReferenceTransactionHash: txi.ReferenceTransactionHash, OutputIndex: txi.OutputIndex}] {
+				delete(w.Pending, hash)
+				break
+			}
+		}
+	}
+}
+
+// sign fills in the unlocking scripts of a transaction the
+// wallet makes, which spends coins (see
+// block.TransactionOutput.MakeSignature).
+func (w *Wallet) sign(tx *block.Transaction, coins []*CoinInfo) {
+	for _, txi := range tx.Inputs {
+		if txo, err := spendable(coins).Coin(txi); err == nil {
+			txi.UnlockingScript, _ = txo.MakeSignature(w.Id, tx)
+		}
+	}
+}
+
+// spendable are the coins a transaction the wallet makes spends
+// (see validation.Coins).
+type spendable []*CoinInfo
+
+func (s spendable) Coin(txi *block.TransactionInput) (*block.TransactionOutput, error) {
+	for _, coin := range s {
+		if coin.ReferenceTransactionHash == txi.ReferenceTransactionHash && coin.OutputIndex == txi.OutputIndex {
+			return coin.TransactionOutput, nil
+		}
+	}
+	return nil, reject.New(pro.RejectCode_REJECT_MISSING_INPUTS, "", "coin is not in the wallet")
+}
+
 // HandleBlock handles the transactions of a new block. It:
 // (1) sees if any of the inputs are ones that we've spent
 // (2) sees if any of the incoming outputs on the block are ours
@@ -148,18 +300,76 @@ func (w *Wallet) RequestTransaction(amount uint32, fee uint32, recipientPK []byt
 func (w *Wallet) HandleBlock(txs []*block.Transaction) {
 	//TODO
 	for _, tx := range txs {
-		w.checkInputs(tx)
-		w.checkOutputs(tx.Outputs, tx)
+		w.checkInputs(tx, 0)
+		w.checkOutputs(tx.Outputs, tx, 0)
+		w.confirmPending(tx)
 		// third helper that increments by 1 and chceks if it exceeds the limit and delete
 	}
 	w.updateCoin()
 
 }
 
+// HandleTransaction handles a single transaction that is known
+// to be on the chain, without counting a new confirmation. Light
+// clients use it for payments they have verified with a merkle
+// proof, since they never see whole blocks. Coins the wallet
+// already has are left alone, so a payment can be verified more
+// than once.
+// Inputs:
+// tx *block.Transaction the transaction
+// confirmations uint32 the number of blocks on top of, and
+// including, the block the transaction is in
+func (w *Wallet) HandleTransaction(tx *block.Transaction, confirmations uint32) {
+	w.checkInputs(tx, confirmations)
+	w.checkOutputs(tx.Outputs, tx, confirmations)
+	w.confirmPending(tx)
+	w.settleCoins()
+}
+
+// HandleConfirmation counts another confirmation for the
+// wallet's unconfirmed coins. Light clients call it whenever
+// their best header chain grows, since they never see the
+// transactions of the new block.
+func (w *Wallet) HandleConfirmation() {
+	w.updateCoin()
+}
+
+// hasCoin returns whether the wallet already knows about the
+// output at index of the transaction with the given hash,
+// whether it is unconfirmed, confirmed, or already spent.
+func (w *Wallet) hasCoin(hash string, index uint32) bool {
+	is := func(coin *CoinInfo) bool {
+		return coin.ReferenceTransactionHash == hash && coin.OutputIndex == index
+	}
+	for coin := range w.UnconfirmedReceivedCoins {
+		if is(coin) {
+			return true
+		}
+	}
+	for _, coin := range w.CoinCollection {
+		if is(coin) {
+			return true
+		}
+	}
+	for coin := range w.UnconfirmedSpentCoins {
+		if is(coin) {
+			return true
+		}
+	}
+	for _, coins := range w.UnseenSpentCoins {
+		for _, coin := range coins {
+			if is(coin) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // look at other fuctions dealing with putting txs into a block (# txs in a block incorrect)!! otherwise its good
 
 // step (1): sees if any of the inputs are ones that we've spent
-func (w *Wallet) checkInputs(tx *block.Transaction) {
+func (w *Wallet) checkInputs(tx *block.Transaction, confirmations uint32) {
 	//TODO
 	inps := tx.Inputs
 	for _, input := range inps {
@@ -170,7 +380,7 @@ func (w *Wallet) checkInputs(tx *block.Transaction) {
 
 			delete(w.UnseenSpentCoins, hash)
 			for _, coin := range coinInfo {
-				w.UnconfirmedSpentCoins[coin] = 0
+				w.UnconfirmedSpentCoins[coin] = confirmations
 				//w.UnseenSpentCoins[hash] = append(coinInfo[:i], coinInfo[i+1:]...)
 				//if len(w.UnseenSpentCoins[hash]) == 0 {
 				//	delete(w.UnseenSpentCoins, hash)
@@ -181,17 +391,12 @@ func (w *Wallet) checkInputs(tx *block.Transaction) {
 }
 
 // step (2): sees if any of the incoming outputs on the block are ours
-func (w *Wallet) checkOutputs(outs []*block.TransactionOutput, tx *block.Transaction) {
+func (w *Wallet) checkOutputs(outs []*block.TransactionOutput, tx *block.Transaction, confirmations uint32) {
+	hash := tx.Hash()
 	for i, out := range outs {
-		// create coin
-		if out.LockingScript == w.Id.GetPublicKeyString() {
-			coin := &CoinInfo{tx.Hash(), uint32(i), out}
-
-			// check if coin is ours
-			if _, ok := w.UnconfirmedReceivedCoins[coin]; !ok {
-				w.UnconfirmedReceivedCoins[coin] = 0
-			}
-			//w.UnconfirmedReceivedCoins[coin] = 0
+		// check if coin is ours and new to us
+		if out.LockingScript == w.Id.GetPublicKeyString() && !w.hasCoin(hash, uint32(i)) {
+			w.UnconfirmedReceivedCoins[&CoinInfo{hash, uint32(i), out}] = confirmations
 		}
 	}
 }
@@ -203,16 +408,26 @@ func (w *Wallet) updateCoin() {
 	// then delete the unconfirmed coins from that field
 	// otherwise if they haven't reached it then increment the # of confirmations
 
-	for coin, confirm := range w.UnconfirmedSpentCoins {
+	for coin := range w.UnconfirmedSpentCoins {
 		w.UnconfirmedSpentCoins[coin] += 1
-		if (confirm + 1) >= w.Config.SafeBlockAmount { // safe block amount????
+	}
+	for coin := range w.UnconfirmedReceivedCoins {
+		w.UnconfirmedReceivedCoins[coin] += 1
+	}
+	w.settleCoins()
+}
+
+// settleCoins drops the spent coins and credits the received
+// coins that have at least Config.SafeBlockAmount confirmations.
+func (w *Wallet) settleCoins() {
+	for coin, confirm := range w.UnconfirmedSpentCoins {
+		if confirm >= w.Config.SafeBlockAmount {
 			delete(w.UnconfirmedSpentCoins, coin)
 			delete(w.CoinCollection, coin.TransactionOutput)
 		}
 	}
 	for coin, confirm := range w.UnconfirmedReceivedCoins {
-		w.UnconfirmedReceivedCoins[coin] += 1
-		if (confirm + 1) >= w.Config.SafeBlockAmount {
+		if confirm >= w.Config.SafeBlockAmount {
 			w.Balance += coin.TransactionOutput.Amount // add it to balance
 			w.CoinCollection[coin.TransactionOutput] = coin
 			delete(w.UnconfirmedReceivedCoins, coin)
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/address/addressdb"
+	"Coin/pkg/blockchain"
+	"fmt"
+	"testing"
+)
+
+// testBucketKey decides the buckets of the address tables in
+// these tests, so that where addresses land is the same every run.
+var testBucketKey = []byte("address table test key")
+
+func TestSourceFillsFewNewBuckets(t *testing.T) {
+	adb := addressdb.NewKeyedDb(10000, testBucketKey)
+	// a single source sends addresses from many networks
+	for i := 0; i < 1000; i++ {
+		adb.Add(address.New(fmt.Sprintf("10.%v.%v.1:8000", i/256, i%256), 1), "1.2.3.4:8000")
+	}
+	// a source's addresses land in at most 8 buckets
+	if stored := len(adb.List()); stored > 8*addressdb.BucketSize {
+		t.Errorf("Expected at most %v addresses from one source, stored %v", 8*addressdb.BucketSize, stored)
+	}
+	if err := adb.Add(address.New("20.0.0.1:8000", 1), "30.0.0.1:8000"); err != nil {
+		t.Errorf("Expected an address from another source to be added: %v", err)
+	}
+}
+
+func TestGoodAddressesAreSelected(t *testing.T) {
+	adb := addressdb.NewKeyedDb(10000, testBucketKey)
+	for i := 0; i < 100; i++ {
+		adb.Add(address.New(fmt.Sprintf("10.%v.0.1:8000", i), 1), "1.2.3.4:8000")
+	}
+	good := "20.0.0.1:8000"
+	if err := adb.Add(address.New(good, 1), good); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	adb.Good(good)
+	picked := 0
+	for i := 0; i < 200; i++ {
+		if s := adb.Select(1); len(s) == 1 && s[0].Addr == good {
+			picked++
+		}
+	}
+	// the only tried address is picked about half the time
+	if picked < 50 {
+		t.Errorf("Expected the tried address to be picked often, picked %v of 200 times", picked)
+	}
+	if s := adb.Select(200); len(s) != len(adb.List()) {
+		t.Errorf("Expected Select to return every address, got %v of %v", len(s), len(adb.List()))
+	}
+}
+
+func TestHandshakeDoesNotNeedRoomForTheAddress(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	// b's address table has no room left
+	b.AddressDB.Close()
+	b.AddressDB = addressdb.New(true, 1, "")
+	if err := b.AddressDB.Add(address.New("10.0.0.1:8000", 1), "10.0.0.1:8000"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+	if b.PeerDb.Get(a.Address) == nil {
+		t.Errorf("Expected a to become a peer of b even though b can not store its address")
+	}
+}
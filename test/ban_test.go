@@ -0,0 +1,84 @@
+package test
+
+import (
+	"Coin/pkg/blockchain"
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBanListPersistsAndExpires(t *testing.T) {
+	path := "bantest"
+	defer os.RemoveAll(path)
+	bl := peer.NewBanList(path)
+	bl.Ban("a", time.Now().Add(time.Hour), "testing")
+	bl.Ban("b", time.Now().Add(-time.Second), "expired")
+	bl.Close()
+
+	bl = peer.NewBanList(path)
+	defer bl.Close()
+	if !bl.IsBanned("a") {
+		t.Errorf("Expected ban to survive reopening the ban list")
+	}
+	if bl.IsBanned("b") {
+		t.Errorf("Expected expired ban to be lifted")
+	}
+	if bans := bl.List(); len(bans) != 1 || bans[0].Addr != "a" || bans[0].Reason != "testing" {
+		t.Errorf("Expected only the ban of a to be listed, got %v", bans)
+	}
+	if !bl.Unban("a") || bl.IsBanned("a") {
+		t.Errorf("Expected a to be unbanned")
+	}
+
+	// the ban list is locked while bl has it open, so another
+	// one keeps its bans in memory
+	other := peer.NewBanList(path)
+	defer other.Close()
+	other.Ban("c", time.Now().Add(time.Hour), "testing")
+	if !other.IsBanned("c") || bl.IsBanned("c") {
+		t.Errorf("Expected a ban list that can not be opened to keep its bans in memory")
+	}
+}
+
+func TestMisbehavingPeerIsBanned(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+
+	oversized := &pro.Inventory{AddrMe: a.Address}
+	for i := 0; i <= 1000; i++ {
+		oversized.Items = append(oversized.Items, &pro.InventoryItem{Type: pro.InventoryType_INVENTORY_BLOCK, Hash: "x"})
+	}
+	if _, err := b.Announce(context.Background(), oversized); err == nil {
+		t.Fatalf("Expected oversized announcement to fail")
+	}
+	if score := b.PeerDb.Get(a.Address).Misbehavior(); score != peer.OversizedMessage.Score() {
+		t.Fatalf("Expected misbehavior score %v, got %v", peer.OversizedMessage.Score(), score)
+	}
+	for b.PeerDb.Get(a.Address) != nil {
+		b.Announce(context.Background(), oversized)
+	}
+	if bans := b.ListBanned(); len(bans) != 1 || bans[0].Addr != a.Address {
+		t.Fatalf("Expected a to be banned, got %v", bans)
+	}
+
+	// banned addresses can't become peers again until unbanned
+	a.ConnectToPeer(b.Address)
+	if b.PeerDb.Get(a.Address) != nil {
+		t.Errorf("Expected banned address to be refused")
+	}
+	if !b.UnbanPeer(a.Address) {
+		t.Fatalf("Expected a to have been banned")
+	}
+	a.ConnectToPeer(b.Address)
+	if b.PeerDb.Get(a.Address) == nil {
+		t.Errorf("Expected unbanned address to become a peer again")
+	}
+}
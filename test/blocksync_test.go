@@ -0,0 +1,103 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"testing"
+	"time"
+)
+
+func TestBootstrapDownloadsBlocksFromSeveralPeers(t *testing.T) {
+	cluster := NewCluster(4)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain,
+		cluster[2].BlockChain, cluster[3].BlockChain})
+	full, other, paused, syncing := cluster[0], cluster[1], cluster[2], cluster[3]
+	syncing.Config.SyncWindow = 4
+
+	for _, b := range MineChain(full.BlockChain, 40) {
+		other.BlockChain.HandleBlock(b)
+		paused.BlockChain.HandleBlock(b)
+	}
+	StartCluster(cluster)
+	defer full.Kill()
+	defer other.Kill()
+	defer syncing.Kill()
+	syncing.ConnectToPeer(full.Address)
+	syncing.ConnectToPeer(other.Address)
+	syncing.ConnectToPeer(paused.Address)
+	paused.PauseNetwork()
+
+	if err := syncing.Bootstrap(); err != nil {
+		t.Fatalf("Failed to bootstrap: %v", err)
+	}
+	CheckMainChains(t, []*pkg.Node{full, syncing})
+}
+
+func TestBootstrapSwitchesToLongerChain(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	full, syncing := cluster[0], cluster[1]
+
+	MineChain(full.BlockChain, 5)
+	// the syncing node starts on its own, shorter fork
+	ts := uint32(time.Now().Unix())
+	for i := 0; i < 3; i++ {
+		coinbase := &block.Transaction{
+			Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "fork"}},
+			LockTime: syncing.BlockChain.Length,
+		}
+		syncing.BlockChain.HandleBlock(MineBlock(syncing.BlockChain.LastHash, []*block.Transaction{coinbase}, ts+uint32(i)))
+	}
+	if syncing.BlockChain.Length != 4 {
+		t.Fatalf("Expected fork of length 4, got %v", syncing.BlockChain.Length)
+	}
+	StartCluster(cluster)
+	defer full.Kill()
+	defer syncing.Kill()
+	syncing.ConnectToPeer(full.Address)
+
+	if err := syncing.Bootstrap(); err != nil {
+		t.Fatalf("Failed to bootstrap: %v", err)
+	}
+	CheckMainChains(t, cluster)
+	// bootstrapping again has nothing left to do
+	if err := syncing.Bootstrap(); err != nil {
+		t.Errorf("Expected second bootstrap to succeed: %v", err)
+	}
+	CheckMainChains(t, cluster)
+}
+
+func TestBootstrapRejectsBlocksThatCreateCoins(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	full, syncing := cluster[0], cluster[1]
+
+	// the full node's chain (which does not check amounts) ends
+	// in a block that pays out more than it spends
+	blocks := MineChain(full.BlockChain, 2)
+	coinbase := &block.Transaction{
+		Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}},
+		LockTime: full.BlockChain.Length,
+	}
+	inflating := signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: blocks[0].Transactions[0].Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: 1000, LockingScript: "someone"}},
+	})
+	full.BlockChain.HandleBlock(MineBlock(full.BlockChain.LastHash, []*block.Transaction{coinbase, inflating},
+		blocks[1].Header.Timestamp+1))
+	if full.BlockChain.Length != 4 {
+		t.Fatalf("Expected the full node to take the block, got length %v", full.BlockChain.Length)
+	}
+	StartCluster(cluster)
+	defer full.Kill()
+	defer syncing.Kill()
+	syncing.ConnectToPeer(full.Address)
+
+	if err := syncing.Bootstrap(); err == nil {
+		t.Errorf("Expected the block that creates coins to fail the sync")
+	}
+	if syncing.BlockChain.Length != 3 || syncing.BlockChain.LastHash != blocks[1].Hash() {
+		t.Errorf("Expected the syncing node to stop before the invalid block, got length %v", syncing.BlockChain.Length)
+	}
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain/coindatabase"
+	"os"
+	"testing"
+)
+
+func newTestCoinDB(t *testing.T) *coindatabase.CoinDatabase {
+	t.Helper()
+	conf := coindatabase.DefaultConfig()
+	conf.DatabasePath = "coindatatest"
+	coinDB := coindatabase.New(conf)
+	t.Cleanup(func() {
+		coinDB.Close()
+		if err := os.RemoveAll(conf.DatabasePath); err != nil {
+			t.Errorf("could not remove %v", conf.DatabasePath)
+		}
+	})
+	return coinDB
+}
+
+func spend(tx *block.Transaction, amt uint32) *block.Transaction {
+	return signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: tx.Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: amt}},
+	})
+}
+
+func TestValidateBlockRejectsIntraBlockDoubleSpend(t *testing.T) {
+	coinDB := newTestCoinDB(t)
+	gen := GenesisBlock()
+	coinDB.StoreBlock(gen.Transactions)
+	genTx := gen.Transactions[0]
+
+	txs := []*block.Transaction{spend(genTx, 10), spend(genTx, 20)}
+	if coinDB.ValidateBlock(txs) {
+		t.Errorf("Expected block spending the same coin twice to be invalid")
+	}
+	// each transaction on its own is fine
+	for _, tx := range txs {
+		if err := coinDB.ValidateTransaction(tx); err != nil {
+			t.Errorf("Expected transaction to be valid on its own: %v", err)
+		}
+	}
+}
+
+func TestValidateBlockAllowsInBlockChains(t *testing.T) {
+	coinDB := newTestCoinDB(t)
+	gen := GenesisBlock()
+	coinDB.StoreBlock(gen.Transactions)
+
+	tx1 := spend(gen.Transactions[0], 100)
+	tx2 := spend(tx1, 90)
+	if !coinDB.ValidateBlock([]*block.Transaction{tx1, tx2}) {
+		t.Errorf("Expected block with an in-order chain of spends to be valid")
+	}
+	if coinDB.ValidateBlock([]*block.Transaction{tx2, tx1}) {
+		t.Errorf("Expected block spending an output before it is created to be invalid")
+	}
+	if coinDB.ValidateBlock([]*block.Transaction{tx1, tx2, spend(tx1, 80)}) {
+		t.Errorf("Expected block double spending an in-block output to be invalid")
+	}
+
+	coinDB.StoreBlock([]*block.Transaction{tx1, tx2})
+	if err := coinDB.ValidateTransaction(spend(tx1, 80)); err == nil {
+		t.Errorf("Expected output spent within the stored block to be spent")
+	}
+	if err := coinDB.ValidateTransaction(spend(tx2, 80)); err != nil {
+		t.Errorf("Expected output at the end of the chain to be unspent: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"testing"
+	"time"
+)
+
+// mineBlockSpending mines a block on top of the chain that spends
+// the coinbase of each of the given blocks.
+func mineBlockSpending(chain *blockchain.BlockChain, blocks []*block.Block) *block.Block {
+	txs := []*block.Transaction{{
+		Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}},
+		LockTime: chain.Length,
+	}}
+	for _, b := range blocks {
+		txs = append(txs, spend(b.Transactions[0], 10))
+	}
+	return MineBlock(chain.LastHash, txs, chain.LastBlock.Header.Timestamp+1)
+}
+
+func TestCompactBlockRebuild(t *testing.T) {
+	coinbase := &block.Transaction{Outputs: []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}}}
+	txs := []*block.Transaction{coinbase, spend(coinbase, 10), spend(coinbase, 20), spend(coinbase, 30)}
+	b := MineBlock("", txs, uint32(time.Now().Unix()))
+	cb := block.NewCompactBlock(b)
+	if len(cb.Prefilled) != 1 || len(cb.ShortIDs) != 3 {
+		t.Fatalf("Expected a prefilled coinbase and 3 short ids, got %v and %v", len(cb.Prefilled), len(cb.ShortIDs))
+	}
+	cb = block.DecodeCompactBlock(block.EncodeCompactBlock(cb))
+	if cb.Hash() != b.Hash() {
+		t.Fatalf("Expected compact block to keep the block's hash")
+	}
+
+	// the receiver has all but the last transaction
+	pb, err := cb.Rebuild(b.Transactions[1:3])
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if missing := pb.Missing(); len(missing) != 1 || missing[0] != 3 {
+		t.Fatalf("Expected only transaction 3 to be missing, got %v", missing)
+	}
+	if _, err := pb.Block(); err == nil {
+		t.Errorf("Expected a block with missing transactions to fail")
+	}
+	if err := pb.Fill(b.Transactions[1:2]); err != nil {
+		t.Fatalf("Fill failed: %v", err)
+	}
+	if _, err := pb.Block(); err == nil {
+		t.Errorf("Expected a block with the wrong transactions to fail its merkle root")
+	}
+
+	pb, _ = cb.Rebuild(b.Transactions[1:3])
+	if err := pb.Fill(b.Transactions[3:]); err != nil {
+		t.Fatalf("Fill failed: %v", err)
+	}
+	rebuilt, err := pb.Block()
+	if err != nil {
+		t.Fatalf("Block failed: %v", err)
+	}
+	if rebuilt.Hash() != b.Hash() || len(rebuilt.Transactions) != len(b.Transactions) {
+		t.Errorf("Expected the rebuilt block to match the original")
+	}
+}
+
+// relayBlock starts a star of three nodes around a miner, gives
+// the other nodes the transactions of a new block (except that
+// the last node lacks one), has the miner announce the block,
+// and returns how long the block took to reach every node.
+func relayBlock(t *testing.T, compact bool) (time.Duration, []*pkg.Node) {
+	cluster := NewCluster(3)
+	t.Cleanup(func() {
+		CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	})
+	a := cluster[0]
+	blocks := MineChain(a.BlockChain, 3)
+	for _, n := range cluster {
+		n.Config.CompactBlocks = compact
+		if n == a {
+			continue
+		}
+		for _, b := range blocks {
+			n.BlockChain.HandleBlock(b)
+		}
+	}
+	blk := mineBlockSpending(a.BlockChain, blocks)
+	StartCluster(cluster)
+	t.Cleanup(func() {
+		for _, n := range cluster {
+			n.Kill()
+		}
+	})
+	// transactions are handed out before the nodes connect, so
+	// that the last node does not learn the missing one
+	for i, n := range cluster {
+		for j, tx := range blk.Transactions[1:] {
+			if i == len(cluster)-1 && j == len(blocks)-1 {
+				continue
+			}
+			n.BroadcastTransaction(tx)
+		}
+	}
+	a.ConnectToPeer(cluster[1].Address)
+	a.ConnectToPeer(cluster[2].Address)
+
+	start := time.Now()
+	a.HandleMinerBlock(blk)
+	for _, n := range cluster[1:] {
+		waitForLength(t, n, a.BlockChain.Length)
+	}
+	elapsed := time.Since(start)
+	CheckMainChains(t, cluster)
+	return elapsed, cluster
+}
+
+func TestCompactBlocksAreRebuiltFromTheMempool(t *testing.T) {
+	elapsed, cluster := relayBlock(t, true)
+	t.Logf("block propagation with compact blocks: %v", elapsed)
+	full, missing := cluster[1].CompactBlockStats(), cluster[2].CompactBlockStats()
+	if full.Rebuilt != 1 || full.RequestedTransactions != 0 || full.FellBack != 0 {
+		t.Errorf("Expected a node with every transaction to rebuild the block on its own, got %+v", full)
+	}
+	if missing.Rebuilt != 1 || missing.RequestedTransactions != 1 || missing.FellBack != 0 {
+		t.Errorf("Expected a node missing a transaction to request only that one, got %+v", missing)
+	}
+}
+
+func TestFullBlocksAreRelayedWithoutCompactBlocks(t *testing.T) {
+	elapsed, cluster := relayBlock(t, false)
+	t.Logf("block propagation with full blocks: %v", elapsed)
+	for _, n := range cluster {
+		if stats := n.CompactBlockStats(); stats.Rebuilt != 0 || stats.FellBack != 0 {
+			t.Errorf("Expected no compact blocks when they are turned off, got %+v", stats)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/address"
+	"Coin/pkg/blockchain"
+	"testing"
+	"time"
+)
+
+// countPeers returns how many outbound and inbound peers a node has.
+func countPeers(n *pkg.Node) (outbound int, inbound int) {
+	for _, p := range n.PeerDb.List() {
+		if p.Outbound {
+			outbound++
+		} else {
+			inbound++
+		}
+	}
+	return outbound, inbound
+}
+
+// waitForOutbound waits until a node has the given number of outbound peers.
+func waitForOutbound(t *testing.T, n *pkg.Node, want int) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if outbound, _ := countPeers(n); outbound == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			outbound, _ := countPeers(n)
+			t.Fatalf("Timed out waiting for %v outbound peers, have %v", want, outbound)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestConnectionManagerDialsFromSeeds(t *testing.T) {
+	cluster := NewCluster(4)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain, cluster[3].BlockChain})
+	seed, b, c, joining := cluster[0], cluster[1], cluster[2], cluster[3]
+	StartCluster(cluster[:3])
+	defer seed.Kill()
+	defer b.Kill()
+	defer c.Kill()
+	b.ConnectToPeer(seed.Address)
+	c.ConnectToPeer(seed.Address)
+
+	joining.Config.Seeds = []string{seed.Address}
+	joining.Config.MaxOutbound = 2
+	joining.Start()
+	defer joining.Kill()
+	waitForOutbound(t, joining, 2)
+	if joining.PeerDb.Get(seed.Address) == nil {
+		t.Errorf("Expected the joining node to connect to its seed")
+	}
+	// the target is kept, not exceeded
+	time.Sleep(200 * time.Millisecond)
+	if outbound, _ := countPeers(joining); outbound != 2 {
+		t.Errorf("Expected 2 outbound peers, got %v", outbound)
+	}
+}
+
+func TestInboundPeersAreCapped(t *testing.T) {
+	cluster := NewCluster(3)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	a, b, c := cluster[0], cluster[1], cluster[2]
+	a.Config.MaxInbound = 1
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	b.ConnectToPeer(a.Address)
+	c.ConnectToPeer(a.Address)
+	if _, inbound := countPeers(a); inbound != 1 {
+		t.Errorf("Expected 1 inbound peer, got %v", inbound)
+	}
+	if a.PeerDb.Get(c.Address) != nil || c.PeerDb.Get(a.Address) != nil {
+		t.Errorf("Expected c to be refused")
+	}
+	if p := b.PeerDb.Get(a.Address); p == nil || !p.Outbound {
+		t.Errorf("Expected a to be an outbound peer of b")
+	}
+}
+
+func TestConnectOnlyPeers(t *testing.T) {
+	cluster := NewCluster(3)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	a, b, c := cluster[0], cluster[1], cluster[2]
+	StartCluster(cluster[1:])
+	defer b.Kill()
+	defer c.Kill()
+	a.Config.ConnectOnly = []string{b.Address}
+	a.AddressDB.Add(address.New(c.Address, 0), c.Address)
+	a.Start()
+	defer a.Kill()
+	waitForOutbound(t, a, 1)
+	if a.PeerDb.Get(b.Address) == nil || a.PeerDb.Get(c.Address) != nil {
+		t.Errorf("Expected a to connect only to b")
+	}
+}
+
+func TestFailedPeersAreReplaced(t *testing.T) {
+	cluster := NewCluster(3)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	a, b, c := cluster[0], cluster[1], cluster[2]
+	a.Config.MaxOutbound = 1
+	StartCluster(cluster[1:])
+	defer c.Kill()
+	a.Config.Seeds = []string{b.Address, c.Address}
+	a.Start()
+	defer a.Kill()
+	waitForOutbound(t, a, 1)
+	if a.PeerDb.Get(b.Address) == nil {
+		t.Fatalf("Expected a to connect to its first seed")
+	}
+
+	b.Kill()
+	deadline := time.Now().Add(10 * time.Second)
+	for a.PeerDb.Get(c.Address) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for a to replace b")
+		}
+		// requests to b fail until a drops it
+		a.BroadcastAddress()
+		time.Sleep(100 * time.Millisecond)
+	}
+	if a.PeerDb.Get(b.Address) != nil {
+		t.Errorf("Expected b to be dropped")
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/blockchain"
+	"testing"
+	"time"
+)
+
+// waitForPeers waits until a node is peered with every other node.
+func waitForPeers(t *testing.T, n *pkg.Node, others []*pkg.Node) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for _, o := range others {
+		for n.PeerDb.Get(o.Address) == nil {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %v to peer with %v", n.Address, o.Address)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+func TestNodeDiscoversNetworkFromSingleSeed(t *testing.T) {
+	cluster := NewCluster(4)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain, cluster[3].BlockChain})
+	seed, b, c, joining := cluster[0], cluster[1], cluster[2], cluster[3]
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	// a line: seed <-> b <-> c
+	b.ConnectToPeer(seed.Address)
+	c.ConnectToPeer(b.Address)
+
+	joining.ConnectToPeer(seed.Address)
+	waitForPeers(t, joining, []*pkg.Node{seed, b, c})
+}
+
+func TestNodeReadvertisesItsAddress(t *testing.T) {
+	cluster := NewCluster(3)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	a, b, c := cluster[0], cluster[1], cluster[2]
+	c.Config.AddressInterval = 100 * time.Millisecond
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	a.ConnectToPeer(b.Address)
+	// c's address reaches a through b once c advertises it
+	b.ConnectToPeer(c.Address)
+	waitForPeers(t, a, []*pkg.Node{b, c})
+}
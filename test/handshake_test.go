@@ -0,0 +1,71 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/address"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"strings"
+	"testing"
+)
+
+func TestHandshakeRecordsUserAgentAndServices(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	a.Config.UserAgent = "/test:1.0/"
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+	p := b.PeerDb.Get(a.Address)
+	if p == nil {
+		t.Fatalf("Expected a to be a peer of b")
+	}
+	if p.UserAgent != "/test:1.0/" {
+		t.Errorf("Expected a's user agent, got %q", p.UserAgent)
+	}
+	if !p.HasService(peer.ServiceFullChain) || !p.HasService(peer.ServiceMiner) || p.HasService(peer.ServiceLight) {
+		t.Errorf("Expected a to advertise a full chain and a miner, got services %b", p.Services)
+	}
+	if p := a.PeerDb.Get(b.Address); p == nil || p.UserAgent != pkg.DefaultUserAgent {
+		t.Errorf("Expected b to send the default user agent")
+	}
+}
+
+func TestSelfConnectionsAreRejected(t *testing.T) {
+	cluster := NewCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	a := cluster[0]
+	StartCluster(cluster)
+	defer a.Kill()
+	a.ConnectToPeer(a.Address)
+	if a.PeerDb.Get(a.Address) != nil {
+		t.Errorf("Expected a not to become its own peer")
+	}
+}
+
+func TestIncompatibleVersionsAreRejected(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	b.Config.Version = 2
+	b.Config.MinVersion = 2
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+	if a.PeerDb.Get(b.Address) != nil || b.PeerDb.Get(a.Address) != nil {
+		t.Errorf("Expected nodes with incompatible versions not to peer")
+	}
+	_, err := address.New(b.Address, 0).VersionRPC(&pro.VersionRequest{Version: 1, AddrMe: "old:1"})
+	if err == nil || !strings.Contains(err.Error(), "incompatible protocol version") {
+		t.Errorf("Expected an explicit rejection of an old version, got %v", err)
+	}
+	// b speaks only version 2, but this sender needs version 3 or newer
+	_, err = address.New(b.Address, 0).VersionRPC(&pro.VersionRequest{Version: 3, MinVersion: 3, AddrMe: "new:1"})
+	if err == nil || !strings.Contains(err.Error(), "incompatible protocol version") {
+		t.Errorf("Expected an explicit rejection of a node that needs a newer version, got %v", err)
+	}
+}
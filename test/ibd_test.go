@@ -0,0 +1,64 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/address"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/clock"
+	"Coin/pkg/peer"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIBDStartsAndEndsWithPeerHeights(t *testing.T) {
+	now := time.Now()
+	conf := setNodeConfig(GenesisConfig(GetFreePort()), 0)
+	conf.Clock = clock.NewManualClock(now)
+	conf.IBDHeightLag = 5
+	node := pkg.New(conf)
+	defer CleanUp([]*blockchain.BlockChain{node.BlockChain})
+	node.Miner.StartMiner()
+	MineChain(node.BlockChain, 1)
+
+	node.UpdateIBD()
+	if node.InIBD() {
+		t.Fatalf("Expected node without peers not to be in IBD")
+	}
+	ahead := peer.New(address.New("ahead:1", 1), 0, node.BlockChain.Length+6, peer.ServiceFullChain)
+	node.PeerDb.Add(ahead)
+	node.UpdateIBD()
+	if !node.InIBD() {
+		t.Fatalf("Expected node 6 blocks behind a peer to be in IBD")
+	}
+	if node.Miner.Active.Load() {
+		t.Errorf("Expected miner to be paused during IBD")
+	}
+	tx := GenerateTransactions(nil)[0]
+	if _, err := node.ForwardTransaction(context.Background(), block.EncodeTransaction(tx)); err != nil {
+		t.Errorf("Expected transaction to be ignored during IBD: %v", err)
+	}
+	if node.SeenTransactions.Contains(tx.Hash()) || node.Mempool.Count() != 0 {
+		t.Errorf("Expected transaction not to be accepted during IBD")
+	}
+	if p := node.SyncProgress(); p.Height != 2 || p.BestHeight != 8 || p.Percent != 25 {
+		t.Errorf("Unexpected progress %+v", p)
+	}
+
+	MineChain(node.BlockChain, 1)
+	node.UpdateIBD()
+	if node.InIBD() {
+		t.Fatalf("Expected node 5 blocks behind with a fresh tip to leave IBD")
+	}
+	if !node.Miner.Active.Load() || node.Miner.ChainLength.Load() != node.BlockChain.Length {
+		t.Errorf("Expected miner to resume at the new chain length")
+	}
+
+	// a stale tip puts the node back in IBD while it is behind at all
+	conf.Clock.(*clock.ManualClock).Advance(25 * time.Hour)
+	node.UpdateIBD()
+	if !node.InIBD() {
+		t.Errorf("Expected node with a day old tip to be in IBD")
+	}
+}
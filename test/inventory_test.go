@@ -0,0 +1,110 @@
+package test
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/pro"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBlocksAreAnnouncedAcrossTheNetwork(t *testing.T) {
+	cluster := NewCluster(3)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	a, b, c := cluster[0], cluster[1], cluster[2]
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	defer c.Kill()
+	// a line: a <-> b <-> c
+	a.ConnectToPeer(b.Address)
+	b.ConnectToPeer(c.Address)
+
+	coinbase := &block.Transaction{
+		Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}},
+		LockTime: a.BlockChain.Length,
+	}
+	blk := MineBlock(a.BlockChain.LastHash, []*block.Transaction{coinbase}, uint32(time.Now().Unix()))
+	a.HandleMinerBlock(blk)
+	waitForLength(t, c, 2)
+	CheckMainChains(t, cluster)
+	for _, n := range cluster {
+		if !n.SeenBlocks.Contains(blk.Hash()) {
+			t.Errorf("Expected every node to have seen the block")
+		}
+	}
+}
+
+func TestGetObjectsServesKnownObjects(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+
+	coinbase := MineChain(a.BlockChain, 1)[0].Transactions[0]
+	tx := signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: coinbase.Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: 40, LockingScript: "someone"}},
+	})
+	if err := a.BroadcastTransaction(tx); err != nil {
+		t.Fatalf("Expected the transaction to be broadcast: %v", err)
+	}
+	lastHash := a.BlockChain.LastHash
+	res, err := a.GetObjects(context.Background(), &pro.GetObjectsRequest{Items: []*pro.InventoryItem{
+		{Type: pro.InventoryType_INVENTORY_TRANSACTION, Hash: tx.Hash()},
+		{Type: pro.InventoryType_INVENTORY_TRANSACTION, Hash: "unknown"},
+		{Type: pro.InventoryType_INVENTORY_BLOCK, Hash: lastHash},
+		{Type: pro.InventoryType_INVENTORY_BLOCK, Hash: "unknown"},
+	}})
+	if err != nil {
+		t.Fatalf("GetObjects failed: %v", err)
+	}
+	if len(res.Transactions) != 1 || block.DecodeTransaction(res.Transactions[0]).Hash() != tx.Hash() {
+		t.Errorf("Expected only the broadcast transaction, got %v", len(res.Transactions))
+	}
+	if len(res.Blocks) != 1 || block.DecodeBlock(res.Blocks[0]).Hash() != lastHash {
+		t.Errorf("Expected only the last block, got %v", len(res.Blocks))
+	}
+
+	// only peers may announce
+	inv := &pro.Inventory{AddrMe: a.Address, Items: []*pro.InventoryItem{{Type: pro.InventoryType_INVENTORY_BLOCK, Hash: lastHash}}}
+	if _, err := b.Announce(context.Background(), inv); err == nil {
+		t.Errorf("Expected announcement from a node that is not a peer to be rejected")
+	}
+}
+
+func TestOrphanBlocksAreTakenOnceTheirParentIs(t *testing.T) {
+	cluster := NewCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	node := cluster[0]
+	StartCluster(cluster)
+	defer node.Kill()
+
+	coinbase := func(i uint32) []*block.Transaction {
+		return []*block.Transaction{{
+			Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}},
+			LockTime: i,
+		}}
+	}
+	forward := func(b *block.Block) error {
+		_, err := node.ForwardBlock(context.Background(), block.EncodeBlock(b))
+		return err
+	}
+	now := uint32(time.Now().Unix())
+	parent := MineBlock(node.BlockChain.LastHash, coinbase(1), now)
+	child := MineBlock(parent.Hash(), coinbase(2), now+1)
+	if err := forward(child); err == nil {
+		t.Errorf("Expected a block without its parent to be rejected")
+	}
+	for _, b := range []*block.Block{parent, child} {
+		if err := forward(b); err != nil {
+			t.Fatalf("Expected block to be accepted: %v", err)
+		}
+	}
+	if node.BlockChain.LastHash != child.Hash() {
+		t.Errorf("Expected the orphan to be on the chain once its parent is")
+	}
+}
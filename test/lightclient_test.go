@@ -0,0 +1,107 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLightClientSyncsHeadersAndVerifiesPayments(t *testing.T) {
+	full := NewGenesisNode()
+	light := pkg.New(setNodeConfig(pkg.LightConfig(GetFreePort()), 1))
+	defer func() {
+		light.HeaderChain.Close()
+		os.RemoveAll("blockinfodata1")
+		os.RemoveAll("banlist1")
+		os.RemoveAll("addressdata1")
+		os.RemoveAll("peerdata1")
+	}()
+	defer CleanUp([]*blockchain.BlockChain{full.BlockChain})
+
+	MineChain(full.BlockChain, 3)
+	payment := &block.Transaction{
+		Outputs: []*block.TransactionOutput{{Amount: 40, LockingScript: light.Wallet.Id.GetPublicKeyString()}},
+	}
+	paid := MineBlock(full.BlockChain.LastHash, []*block.Transaction{payment}, uint32(time.Now().Unix()+10))
+	full.BlockChain.HandleBlock(paid)
+	MineChain(full.BlockChain, 2)
+
+	StartCluster([]*pkg.Node{full, light})
+	defer full.Kill()
+	defer light.Kill()
+	light.ConnectToPeer(full.Address)
+	if err := light.Bootstrap(); err != nil {
+		t.Fatalf("Light client failed to bootstrap: %v", err)
+	}
+	if light.HeaderChain.Length != full.BlockChain.Length {
+		t.Errorf("Expected header chain length %v, got %v", full.BlockChain.Length, light.HeaderChain.Length)
+	}
+	if light.HeaderChain.LastHash != full.BlockChain.LastHash {
+		t.Errorf("Expected light client to have the same tip as the full node")
+	}
+
+	confirmations, err := light.VerifyPayment(payment, paid.Hash())
+	if err != nil {
+		t.Fatalf("Expected payment to be verified: %v", err)
+	}
+	if confirmations != 3 {
+		t.Errorf("Expected 3 confirmations, got %v", confirmations)
+	}
+	if _, err := light.VerifyPayment(payment, paid.Hash()); err != nil {
+		t.Fatalf("Expected payment to be verified again: %v", err)
+	}
+	if len(light.Wallet.UnconfirmedReceivedCoins) != 1 {
+		t.Errorf("Expected the payment to be credited once, got %v coins", len(light.Wallet.UnconfirmedReceivedCoins))
+	}
+	for _, confirm := range light.Wallet.UnconfirmedReceivedCoins {
+		if confirm != 3 {
+			t.Errorf("Expected the wallet to count 3 confirmations, got %v", confirm)
+		}
+	}
+	if _, err := light.VerifyPayment(payment, full.BlockChain.LastHash); err == nil {
+		t.Errorf("Expected payment to fail verification against a block that does not have it")
+	}
+}
+
+func TestHeaderChainRejectsInvalidHeaders(t *testing.T) {
+	conf := blockchain.DefaultConfig()
+	conf.BlockInfoDBPath = "blockinfodatatest"
+	conf.POWLimit = testPOWLimit
+	hc := blockchain.NewHeaderChain(conf)
+	defer func() {
+		hc.Close()
+		os.RemoveAll(conf.BlockInfoDBPath)
+	}()
+	txs := GenerateTransactions(nil)[:1]
+	ts := uint32(time.Now().Unix())
+
+	orphan := MineBlock("unknown", txs, ts)
+	if _, err := hc.HandleHeader(orphan.Header); err == nil {
+		t.Errorf("Expected header with an unknown parent to be rejected")
+	}
+	noWork := block.New(hc.LastHash, txs, string(CreateDifficultyTarget(3)), ts)
+	for noWork.Header.MeetsTarget() {
+		noWork.Header.Nonce++
+	}
+	if _, err := hc.HandleHeader(noWork.Header); err == nil {
+		t.Errorf("Expected header without proof of work to be rejected")
+	}
+	easy := block.New(hc.LastHash, txs, string(CreateDifficultyTarget(0)), ts)
+	for !easy.Header.MeetsTarget() {
+		easy.Header.Nonce++
+	}
+	if _, err := hc.HandleHeader(easy.Header); err == nil {
+		t.Errorf("Expected header with a target easier than the limit to be rejected")
+	}
+	good := MineBlock(hc.LastHash, txs, ts)
+	if newTip, err := hc.HandleHeader(good.Header); err != nil || !newTip {
+		t.Errorf("Expected valid header to extend the chain: %v", err)
+	}
+	stale := MineBlock(hc.LastHash, txs, 0)
+	if _, err := hc.HandleHeader(stale.Header); err == nil {
+		t.Errorf("Expected header below the median time past to be rejected")
+	}
+}
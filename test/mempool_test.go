@@ -0,0 +1,252 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/mempool"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/validation"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMempoolParentsConflictsAndSelection(t *testing.T) {
+	now := time.Now()
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: fakeCoins{"a:0": 100, "b:0": 100}, Now: now}
+	mp := mempool.New(0, 0, 100)
+
+	parent := spendTx([]string{"a"}, 99)
+	child := spendTx([]string{parent.Hash()}, 89)
+	rich := spendTx([]string{"b"}, 50)
+	for _, tx := range []*block.Transaction{child, parent} {
+		if _, err := mp.Add(c, tx); tx == child && reject.Code(err) != pro.RejectCode_REJECT_MISSING_INPUTS {
+			t.Errorf("Expected a child without its parent to miss inputs, got %v", err)
+		} else if tx == parent && err != nil {
+			t.Fatalf("Expected parent to be added: %v", err)
+		}
+	}
+	for _, tx := range []*block.Transaction{child, rich} {
+		if _, err := mp.Add(c, tx); err != nil {
+			t.Fatalf("Expected transaction to be added: %v", err)
+		}
+	}
+	if _, err := mp.Add(c, parent); reject.Code(err) != pro.RejectCode_REJECT_DUPLICATE {
+		t.Errorf("Expected a transaction added twice to be a duplicate, got %v", err)
+	}
+	_, err := mp.Add(c, spendTx([]string{"a"}, 98))
+	if reject.Code(err) != pro.RejectCode_REJECT_INSUFFICIENT_FEE || !validation.IsPolicy(err) {
+		t.Errorf("Expected a conflicting transaction that pays too little to be rejected by policy, got %v", err)
+	}
+	if fee := mp.Get(child.Hash()).Fee; fee != 10 {
+		t.Errorf("Expected the child to pay 10 on top of its unconfirmed parent, got %v", fee)
+	}
+
+	// the child pays the most per byte, but has to come after its parent
+	selected := mp.Select(1 << 20)
+	order := map[string]int{}
+	for i, e := range selected {
+		order[e.Hash] = i
+	}
+	if len(selected) != 3 || order[rich.Hash()] != 0 || order[parent.Hash()] > order[child.Hash()] {
+		t.Errorf("Expected the selection to be by fee rate with parents first, got %v", order)
+	}
+	// with room for two, the child can not be picked before its parent
+	selected = mp.Select(rich.Size() + parent.Size())
+	if len(selected) != 2 || selected[0].Hash != rich.Hash() || selected[1].Hash != parent.Hash() {
+		t.Errorf("Expected the best transaction and the parent to fit, got %v entries", len(selected))
+	}
+}
+
+func TestMempoolEvictionAndExpiry(t *testing.T) {
+	now := time.Now()
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: fakeCoins{"a:0": 100, "b:0": 100, "c:0": 100}, Now: now}
+	cheap := spendTx([]string{"a"}, 99)
+	pricey := spendTx([]string{"b"}, 50)
+	mp := mempool.New(cheap.Size()+pricey.Size(), time.Hour, 100)
+	for _, tx := range []*block.Transaction{cheap, pricey} {
+		if _, err := mp.Add(c, tx); err != nil {
+			t.Fatalf("Expected transaction to be added: %v", err)
+		}
+	}
+
+	// a full mempool evicts whatever pays least per byte
+	middle := spendTx([]string{"c"}, 90)
+	if _, err := mp.Add(c, middle); err != nil {
+		t.Fatalf("Expected a better paying transaction to enter the full mempool: %v", err)
+	}
+	if mp.Has(cheap.Hash()) || mp.Stats().Evicted != 1 {
+		t.Errorf("Expected the cheapest transaction to be evicted")
+	}
+	_, err := mp.Add(c, spendTx([]string{"a"}, 98))
+	if reject.Code(err) != pro.RejectCode_REJECT_INSUFFICIENT_FEE || !validation.IsPolicy(err) {
+		t.Errorf("Expected a transaction paying too little for the full mempool to be rejected, got %v", err)
+	}
+
+	// old entries expire along with their children
+	child := spendTx([]string{pricey.Hash()}, 40)
+	mp.MaxSize = 0
+	later := *c
+	later.Now = now.Add(40 * time.Minute)
+	if _, err := mp.Add(&later, child); err != nil {
+		t.Fatalf("Expected child to be added: %v", err)
+	}
+	mp.Expire(now.Add(61 * time.Minute))
+	if mp.Count() != 0 || mp.Stats().Expired != 3 {
+		t.Errorf("Expected every transaction to expire with its parent, %v are left", mp.Count())
+	}
+}
+
+func TestMempoolFollowsTheChain(t *testing.T) {
+	conf := setNodeConfig(pkg.DefaultConfig(GetFreePort()), 0)
+	conf.MinerConfig.HasMiner = false
+	node := pkg.New(conf)
+	defer CleanUp([]*blockchain.BlockChain{node.BlockChain})
+	if node.Mempool == nil {
+		t.Fatalf("Expected a node without a miner to have a mempool")
+	}
+	blocks := MineChain(node.BlockChain, 2)
+	coinbase := blocks[0].Transactions[0]
+	ts := blocks[1].Header.Timestamp + 1
+	mine := func(prevHash string, i uint32, txs ...*block.Transaction) *block.Block {
+		cb := &block.Transaction{Outputs: []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}}, LockTime: 100 + i}
+		return MineBlock(prevHash, append([]*block.Transaction{cb}, txs...), ts+i)
+	}
+	forward := func(tx *block.Transaction) error {
+		t.Helper()
+		_, err := node.ForwardTransaction(context.Background(), block.EncodeTransaction(tx))
+		return err
+	}
+
+	parent := signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: coinbase.Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: 45, LockingScript: owner.GetPublicKeyString()}},
+	})
+	child := spendTx([]string{parent.Hash()}, 40)
+	// a child that arrives before its parent is taken once the parent is
+	if err := forward(child); reject.Code(err) != pro.RejectCode_REJECT_MISSING_INPUTS {
+		t.Errorf("Expected a child without its parent to miss inputs, got %v", err)
+	}
+	for _, tx := range []*block.Transaction{parent, child} {
+		if err := forward(tx); err != nil {
+			t.Fatalf("Expected transaction to be accepted: %v", err)
+		}
+		CheckTransactionInMempool(t, node, tx)
+	}
+	conflict := signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: coinbase.Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: 44, LockingScript: "someone else"}},
+	})
+	if err := forward(conflict); reject.Code(err) != pro.RejectCode_REJECT_INSUFFICIENT_FEE {
+		t.Errorf("Expected a conflicting transaction that does not pay for a replacement to be rejected, got %v", err)
+	}
+
+	// the parent is mined, and the child waits for the next block
+	confirmed := mine(node.BlockChain.LastHash, 0, parent)
+	if _, err := node.ForwardBlock(context.Background(), block.EncodeBlock(confirmed)); err != nil {
+		t.Fatalf("Expected block to be accepted: %v", err)
+	}
+	if node.Mempool.Has(parent.Hash()) || !node.Mempool.Has(child.Hash()) {
+		t.Errorf("Expected only the mined parent to leave the mempool")
+	}
+
+	// a longer branch without the parent brings it back
+	fork1 := mine(blocks[1].Hash(), 1)
+	fork2 := mine(fork1.Hash(), 2)
+	for _, b := range []*block.Block{fork1, fork2} {
+		if _, err := node.ForwardBlock(context.Background(), block.EncodeBlock(b)); err != nil {
+			t.Fatalf("Expected fork block to be accepted: %v", err)
+		}
+	}
+	if node.BlockChain.LastHash != fork2.Hash() {
+		t.Fatalf("Expected the longer branch to become the main chain")
+	}
+	if !node.Mempool.Has(parent.Hash()) || !node.Mempool.Has(child.Hash()) || node.MempoolStats().Resurrected != 1 {
+		t.Errorf("Expected the parent of the disconnected block to be back with its child: %+v", node.MempoolStats())
+	}
+	if txs := node.Mempool.Transactions(); len(txs) != 2 || txs[0].Hash() != parent.Hash() {
+		t.Errorf("Expected the resurrected parent to come before its child")
+	}
+
+	// a block that spends the parent's coin elsewhere evicts both
+	if _, err := node.ForwardBlock(context.Background(), block.EncodeBlock(mine(fork2.Hash(), 3, conflict))); err != nil {
+		t.Fatalf("Expected block to be accepted: %v", err)
+	}
+	if node.Mempool.Count() != 0 || node.MempoolStats().Conflicted != 2 {
+		t.Errorf("Expected conflicting transactions to be removed: %+v", node.MempoolStats())
+	}
+}
+
+func TestMinerBuildsFromMempool(t *testing.T) {
+	node := NewGenesisNode()
+	defer CleanUp([]*blockchain.BlockChain{node.BlockChain})
+	blocks := MineChain(node.BlockChain, 1)
+	coinbase := blocks[0].Transactions[0]
+	tx := signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: coinbase.Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: 30, LockingScript: "someone"}},
+	})
+	if _, err := node.ForwardTransaction(context.Background(), block.EncodeTransaction(tx)); err != nil {
+		t.Fatalf("Expected transaction to be accepted: %v", err)
+	}
+	pool := node.Miner.NewMiningPool()
+	if txs := pool.Transactions(); len(txs) != 1 || txs[0].Hash() != tx.Hash() {
+		t.Fatalf("Expected the miner to build from the mempool")
+	}
+	reward := node.Miner.CalculateMintingReward()
+	if cb := node.Miner.GenerateCoinbaseTransaction(pool); cb.Outputs[0].Amount != reward+20 {
+		t.Errorf("Expected the coinbase to collect the fee, got %v", cb.Outputs[0].Amount)
+	}
+}
+
+func TestMempoolSurvivesRestart(t *testing.T) {
+	// both runs start from a genesis block that pays something
+	config := func(i int) *pkg.Config {
+		conf := setNodeConfig(GenesisConfig(GetFreePort()), i)
+		conf.ChainConfig.InitialSubsidy = 100
+		conf.ChainConfig.GenesisPublicKey = owner.GetPublicKeyString()
+		return conf
+	}
+	node := pkg.New(config(0))
+	chains := []*blockchain.BlockChain{node.BlockChain}
+	defer func() { CleanUp(chains) }()
+	node.Start()
+	genesis := node.BlockChain.LastBlock.Transactions[0]
+	coinbase := MineChain(node.BlockChain, 1)[0].Transactions[0]
+	kept := signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: genesis.Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: genesis.Outputs[0].Amount - 10, LockingScript: owner.GetPublicKeyString()}},
+	})
+	gone := signTx(&block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: coinbase.Hash(), OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: 40, LockingScript: "someone"}},
+	})
+	for _, tx := range []*block.Transaction{kept, gone} {
+		if _, err := node.ForwardTransaction(context.Background(), block.EncodeTransaction(tx)); err != nil {
+			t.Fatalf("Expected transaction to be accepted: %v", err)
+		}
+	}
+	old := spendTx([]string{kept.Hash()}, kept.Outputs[0].Amount-10)
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: node.BlockChain.CoinDB,
+		Now: time.Now().Add(-node.Config.MempoolExpiry - time.Hour)}
+	if _, err := node.Mempool.Add(c, old); err != nil {
+		t.Fatalf("Expected old transaction to be added: %v", err)
+	}
+	node.Kill()
+
+	// the restarted node has its own chain, which does not have
+	// the block that gone spends from
+	conf := config(1)
+	conf.MempoolPath = node.Config.MempoolPath
+	restarted := pkg.New(conf)
+	chains = append(chains, restarted.BlockChain)
+	defer restarted.Kill()
+	if !restarted.Mempool.Has(kept.Hash()) || restarted.Mempool.Count() != 1 {
+		t.Errorf("Expected only the transaction that is still valid to be loaded, got %v", restarted.Mempool.Count())
+	}
+	if restarted.MempoolStats().Expired != 1 {
+		t.Errorf("Expected the old transaction to expire: %+v", restarted.MempoolStats())
+	}
+}
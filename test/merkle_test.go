@@ -0,0 +1,47 @@
+package test
+
+import (
+	"Coin/pkg/block"
+	"testing"
+)
+
+func TestMerkleProofs(t *testing.T) {
+	all := GenerateTransactions(nil)
+	for n := 1; n <= len(all); n++ {
+		txs := all[:n]
+		root := block.CalculateMerkleRoot(txs)
+		for i, tx := range txs {
+			mp, err := block.NewMerkleProof(txs, tx.Hash())
+			if err != nil {
+				t.Fatalf("Failed to build proof for tx %v of %v: %v", i, n, err)
+			}
+			if mp.Index != uint32(i) {
+				t.Errorf("Expected proof index %v, got %v", i, mp.Index)
+			}
+			if !block.DecodeMerkleProof(block.EncodeMerkleProof(mp)).Verify(root) {
+				t.Errorf("Expected proof for tx %v of %v to verify", i, n)
+			}
+		}
+	}
+}
+
+func TestMerkleProofRejectsTampering(t *testing.T) {
+	txs := GenerateTransactions(nil)[:5]
+	root := block.CalculateMerkleRoot(txs)
+	mp, _ := block.NewMerkleProof(txs, txs[2].Hash())
+	if mp.Verify(block.CalculateMerkleRoot(txs[:4])) {
+		t.Errorf("Expected proof not to verify against another root")
+	}
+	mp.Index = 3
+	if mp.Verify(root) {
+		t.Errorf("Expected proof with the wrong index not to verify")
+	}
+	mp.Index = 2
+	mp.TransactionHash = txs[1].Hash()
+	if mp.Verify(root) {
+		t.Errorf("Expected proof for another transaction not to verify")
+	}
+	if _, err := block.NewMerkleProof(txs, GenerateTransactions(nil)[9].Hash()); err == nil {
+		t.Errorf("Expected an error for a transaction that is not in the block")
+	}
+}
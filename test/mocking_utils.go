@@ -176,6 +176,8 @@ func MockedBlockWithNCoins(w *wallet.Wallet, n uint32, amt uint32) *block.Block
 	for i := uint32(0); i < n; i++ {
 		tx := CreateMockedTransaction([]uint32{amt}, []uint32{amt})
 		tx.Outputs[0].LockingScript = w.Id.GetPublicKeyString()
+		// spend a different coin, so that every transaction is different
+		tx.Inputs[0].OutputIndex = i
 		txs = append(txs, tx)
 	}
 	b := MockedBlock()
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/address"
+	"Coin/pkg/address/addressdb"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/peer"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddressDbPersistsAddresses(t *testing.T) {
+	path := "addresstest"
+	defer os.RemoveAll(path)
+	adb := addressdb.New(false, 10, path)
+	if err := adb.Add(address.New("a", 1), "a"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := adb.UpdateLastSeen("a", 2); err != nil {
+		t.Fatalf("UpdateLastSeen failed: %v", err)
+	}
+	adb.Close()
+
+	adb = addressdb.New(false, 10, path)
+	defer adb.Close()
+	if a := adb.Get("a"); a == nil || a.LastSeen() != 2 {
+		t.Errorf("Expected address with its last seen time to survive reopening, got %v", a)
+	}
+}
+
+func TestPeerDbForgetsRemovedPeers(t *testing.T) {
+	path := "peertest"
+	defer os.RemoveAll(path)
+	pdb := peer.NewDb(false, 10, "", path)
+	pdb.Add(peer.New(address.New("a", 1), 0, 5, peer.ServiceFullChain))
+	pdb.Add(peer.New(address.New("b", 1), 0, 0, peer.ServiceFullChain))
+	pdb.Remove("b")
+	pdb.Close()
+
+	pdb = peer.NewDb(false, 10, "", path)
+	defer pdb.Close()
+	if len(pdb.List()) != 0 {
+		t.Errorf("Expected saved peers not to be connected")
+	}
+	saved := pdb.Saved()
+	if len(saved) != 1 || saved[0].Addr.Addr != "a" || saved[0].BestHeight() != 5 {
+		t.Errorf("Expected only peer a to be saved, got %v", saved)
+	}
+}
+
+func TestPeerDbIsSafeForConcurrentUse(t *testing.T) {
+	pdb := peer.NewDb(true, 100, "", "")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				addr := fmt.Sprint(i*10 + j)
+				pdb.Add(peer.New(address.New(addr, 1), 0, 0, peer.ServiceFullChain))
+				pdb.UpdateLastSeen(addr, 2)
+				pdb.List()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(pdb.List()) != 80 {
+		t.Errorf("Expected every peer to be added, got %v", len(pdb.List()))
+	}
+}
+
+func TestNodeReconnectsToSavedPeers(t *testing.T) {
+	cluster := NewCluster(2)
+	a, b := cluster[0], cluster[1]
+	chains := []*blockchain.BlockChain{a.BlockChain, b.BlockChain}
+	defer func() { CleanUp(chains) }()
+	b.Config.VersionTimeout = 100 * time.Millisecond
+	StartCluster(cluster)
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+	if a.PeerDb.Get(b.Address) == nil {
+		t.Fatalf("Expected a and b to be peers")
+	}
+	a.Kill()
+	// b ignores versions from a until its own version to a times out,
+	// and a real restart takes longer than that
+	time.Sleep(b.Config.VersionTimeout)
+
+	// the restarted node keeps a's peers and addresses, but
+	// needs its own chain
+	conf := setNodeConfig(GenesisConfig(a.Config.Port), 2)
+	conf.AddressDBPath = a.Config.AddressDBPath
+	conf.PeerDBPath = a.Config.PeerDBPath
+	restarted := pkg.New(conf)
+	chains = append(chains, restarted.BlockChain)
+	restarted.Start()
+	defer restarted.Kill()
+	deadline := time.Now().Add(5 * time.Second)
+	for restarted.PeerDb.Get(b.Address) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the restarted node to reconnect")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if restarted.AddressDB.Get(b.Address) == nil {
+		t.Errorf("Expected the restarted node to remember b's address")
+	}
+}
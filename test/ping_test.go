@@ -0,0 +1,112 @@
+package test
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/peer"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"testing"
+	"time"
+)
+
+func TestPingMeasuresRTT(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	a.Config.PingInterval = 50 * time.Millisecond
+	a.Config.StaleTimeout = 300 * time.Millisecond
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info := a.PeerInfo()
+		if len(info) == 1 && info[0].RTT > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for a to measure the RTT of b")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	// answered pings keep the peer from going stale
+	time.Sleep(time.Second)
+	if a.PeerDb.Get(b.Address) == nil {
+		t.Errorf("Expected a responsive peer to be kept")
+	}
+}
+
+func TestOnlyOnePingIsOutstanding(t *testing.T) {
+	p := peer.New(address.New("a", 1), 0, 0, peer.ServiceFullChain)
+	nonce, ok := p.StartPing()
+	if !ok {
+		t.Fatalf("Expected a ping to start")
+	}
+	if _, ok := p.StartPing(); ok {
+		t.Errorf("Expected no ping to start while another is outstanding")
+	}
+	if _, ok := p.PongReceived(nonce + 2); ok {
+		t.Errorf("Expected a pong with the wrong nonce not to answer the ping")
+	}
+	if _, ok := p.PongReceived(nonce); !ok {
+		t.Errorf("Expected the pong to answer the outstanding ping")
+	}
+	if _, ok := p.StartPing(); !ok {
+		t.Errorf("Expected a ping to start once the last one was answered")
+	}
+}
+
+func TestUnresponsivePeersAreEvicted(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	a.Config.PingInterval = 50 * time.Millisecond
+	a.Config.StaleTimeout = 300 * time.Millisecond
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+	if a.PeerDb.Get(b.Address) == nil {
+		t.Fatalf("Expected a to connect to b")
+	}
+	b.PauseNetwork()
+	deadline := time.Now().Add(5 * time.Second)
+	for a.PeerDb.Get(b.Address) != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for a to evict b")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestPeersThatRejectPingsAreKept(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	a.Config.PingInterval = 50 * time.Millisecond
+	a.Config.StaleTimeout = 300 * time.Millisecond
+	b.Config.GlobalRequestRate = 0.01
+	b.Config.GlobalRequestBurst = 50
+	StartCluster(cluster)
+	defer a.Kill()
+	defer b.Kill()
+	a.ConnectToPeer(b.Address)
+	if a.PeerDb.Get(b.Address) == nil {
+		t.Fatalf("Expected a to connect to b")
+	}
+	// use up b's requests, so that it turns a's pings away
+	flooder := address.New(b.Address, 0)
+	flooder.Transport = address.NewTransport(nil)
+	for i := 0; i < 100; i++ {
+		flooder.PingRPC(&pro.PingRequest{AddrMe: "flooder"})
+	}
+	if _, err := address.New(b.Address, 0).PingRPC(&pro.PingRequest{AddrMe: a.Address}); reject.Code(err) != pro.RejectCode_REJECT_RATE_LIMITED {
+		t.Fatalf("Expected b to turn pings away, got %v", err)
+	}
+	time.Sleep(time.Second)
+	if a.PeerDb.Get(b.Address) == nil {
+		t.Errorf("Expected a peer that rejects pings to be kept")
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/pro"
+	"Coin/pkg/ratelimit"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenBuckets(t *testing.T) {
+	b := ratelimit.NewBucket(10, 5)
+	now := time.Now()
+	if !b.Take(5, now) || b.Take(1, now) {
+		t.Errorf("Expected the bucket to hold 5 tokens")
+	}
+	if !b.Take(5, now.Add(time.Second)) {
+		t.Errorf("Expected the bucket to refill up to its burst")
+	}
+
+	l := ratelimit.NewLimiter(100, 8, 10, 5)
+	if err := l.Allow("a", 5); err != nil {
+		t.Errorf("Expected a's first requests to be allowed: %v", err)
+	}
+	if err := l.Allow("a", 1); err != ratelimit.ErrKeyLimit {
+		t.Errorf("Expected a to be over its limit, got %v", err)
+	}
+	if err := l.Allow("b", 4); err != ratelimit.ErrGlobalLimit {
+		t.Errorf("Expected the global limit to be hit, got %v", err)
+	}
+	if err := l.Allow("b", 3); err != nil {
+		t.Errorf("Expected b's request to fit, got %v", err)
+	}
+}
+
+func TestFloodingIsLimitedByConnection(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	b.Config.PeerRequestRate = 1
+	b.Config.PeerRequestBurst = 20
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	a.ConnectToPeer(b.Address)
+	if b.PeerDb.Get(a.Address) == nil {
+		t.Fatalf("Expected a to be a peer of b")
+	}
+	// the flood comes over a connection of its own, claiming to be a
+	flooder := address.New(b.Address, 0)
+	flooder.Transport = address.NewTransport(nil)
+	limited := 0
+	for i := 0; i < 200; i++ {
+		if _, err := flooder.PingRPC(&pro.PingRequest{AddrMe: a.Address}); err != nil {
+			limited++
+		}
+	}
+	if limited == 0 {
+		t.Errorf("Expected some of the flood to be turned away")
+	}
+	if b.BanList.IsBanned(a.Address) || b.PeerDb.Get(a.Address) == nil {
+		t.Errorf("Expected a not to pay for requests that only claim to come from it")
+	}
+	// a is still served over its own session
+	addr := address.New(b.Address, 0)
+	addr.Transport = a.Transport
+	if _, err := addr.PingRPC(&pro.PingRequest{AddrMe: a.Address}); err != nil {
+		t.Errorf("Expected b to still serve a: %v", err)
+	}
+}
+
+func TestFloodingPeersAreBanned(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	b.Config.PeerRequestRate = 1
+	b.Config.PeerRequestBurst = 20
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	a.ConnectToPeer(b.Address)
+	if b.PeerDb.Get(a.Address) == nil {
+		t.Fatalf("Expected a to be a peer of b")
+	}
+	// the flood comes over the session a peered over
+	addr := address.New(b.Address, 0)
+	addr.Transport = a.Transport
+	for i := 0; i < 200 && !b.BanList.IsBanned(a.Address); i++ {
+		addr.PingRPC(&pro.PingRequest{AddrMe: a.Address})
+	}
+	if !b.BanList.IsBanned(a.Address) {
+		t.Errorf("Expected a to be banned for flooding b")
+	}
+}
+
+func TestFloodingAuthenticatedPeersAreBanned(t *testing.T) {
+	cluster := newTLSCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	a, b := cluster[0], cluster[1]
+	b.Config.PeerRequestRate = 1
+	b.Config.PeerRequestBurst = 20
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	a.ConnectToPeer(b.Address)
+	if b.PeerDb.Get(a.Address) == nil {
+		t.Fatalf("Expected a to be a peer of b")
+	}
+	addr := address.New(b.Address, 0)
+	addr.Transport = a.Transport
+	for i := 0; i < 200 && !b.BanList.IsBanned(a.Address); i++ {
+		addr.PingRPC(&pro.PingRequest{AddrMe: a.Address})
+	}
+	if !b.BanList.IsBanned(a.Address) {
+		t.Errorf("Expected a to be banned for flooding b")
+	}
+}
+
+func TestOversizedMessagesAreRejected(t *testing.T) {
+	cluster := NewCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	a := cluster[0]
+	a.Config.MaxMessageSize = 1024
+	StartCluster(cluster)
+	defer a.Kill()
+	addrs := []*pro.Address{{Addr: strings.Repeat("x", 2048)}}
+	if _, err := address.New(a.Address, 0).SendAddressesRPC(&pro.Addresses{Addrs: addrs}); err == nil {
+		t.Errorf("Expected a message over the size limit to be rejected")
+	}
+	if _, err := address.New(a.Address, 0).GetAddressesRPC(&pro.Empty{}); err != nil {
+		t.Errorf("Expected small messages to still be handled: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRejectCodesReachTheSender(t *testing.T) {
+	cluster := NewCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	node := cluster[0]
+	node.Config.MinVersion = 1
+	StartCluster(cluster)
+	defer node.Kill()
+	addr := address.New(node.Address, 0)
+
+	expectReject := func(what string, err error, code pro.RejectCode, hash string) {
+		t.Helper()
+		rej, ok := err.(*reject.Error)
+		if !ok {
+			t.Errorf("Expected %v to be rejected with a code, got %v", what, err)
+			return
+		}
+		if rej.Code != code || rej.Hash != hash || rej.Reason == "" {
+			t.Errorf("Expected %v to be rejected with %v for %q, got %v for %q (%v)",
+				what, code, hash, rej.Code, rej.Hash, rej.Reason)
+		}
+	}
+	coinbase := func(i uint32) []*block.Transaction {
+		return []*block.Transaction{{
+			Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}},
+			LockTime: i,
+		}}
+	}
+
+	now := uint32(time.Now().Unix())
+	good := MineBlock(node.BlockChain.LastHash, coinbase(1), now)
+	if _, err := addr.ForwardBlockRPC(block.EncodeBlock(good)); err != nil {
+		t.Fatalf("Expected a valid block to be accepted: %v", err)
+	}
+	_, err := addr.ForwardBlockRPC(block.EncodeBlock(good))
+	expectReject("a block sent twice", err, pro.RejectCode_REJECT_DUPLICATE, good.Hash())
+
+	orphan := MineBlock(good.Hash()[1:]+"0", coinbase(2), now+1)
+	_, err = addr.ForwardBlockRPC(block.EncodeBlock(orphan))
+	expectReject("a block with an unknown parent", err, pro.RejectCode_REJECT_ORPHAN, orphan.Hash())
+
+	future := MineBlock(good.Hash(), coinbase(3), uint32(time.Now().Add(3*time.Hour).Unix()))
+	_, err = addr.ForwardBlockRPC(block.EncodeBlock(future))
+	expectReject("a block from the future", err, pro.RejectCode_REJECT_BAD_TIMESTAMP, future.Hash())
+
+	spend := &block.Transaction{
+		Inputs:  []*block.TransactionInput{{ReferenceTransactionHash: "missing", OutputIndex: 0}},
+		Outputs: []*block.TransactionOutput{{Amount: 10, LockingScript: "someone"}},
+	}
+	missing := MineBlock(good.Hash(), append(coinbase(4), spend), now+2)
+	_, err = addr.ForwardBlockRPC(block.EncodeBlock(missing))
+	expectReject("a block spending a missing coin", err, pro.RejectCode_REJECT_MISSING_INPUTS, missing.Hash())
+
+	empty := &block.Transaction{Outputs: []*block.TransactionOutput{{Amount: 10, LockingScript: "someone"}}}
+	_, err = addr.ForwardTransactionRPC(block.EncodeTransaction(empty))
+	expectReject("a transaction without inputs", err, pro.RejectCode_REJECT_MALFORMED, empty.Hash())
+
+	_, err = addr.VersionRPC(&pro.VersionRequest{Version: 0, AddrMe: "old:1"})
+	expectReject("an old version", err, pro.RejectCode_REJECT_OBSOLETE, "")
+}
+
+func TestRejectCodesSurviveWrapping(t *testing.T) {
+	cause := reject.New(pro.RejectCode_REJECT_DOUBLE_SPEND, "", "coin already spent")
+	err := fmt.Errorf("checking input: %w", cause)
+	if reject.Code(err) != pro.RejectCode_REJECT_DOUBLE_SPEND {
+		t.Errorf("Expected the code of a wrapped rejection, got %v", reject.Code(err))
+	}
+	rej := reject.Of(err, "tx")
+	if rej.Code != pro.RejectCode_REJECT_DOUBLE_SPEND || rej.Hash != "tx" || rej.Reason != err.Error() {
+		t.Errorf("Expected the rejection of tx to keep the code and reason, got %+v", rej)
+	}
+	if rej := reject.Of(fmt.Errorf("no code"), "tx"); rej.Code != pro.RejectCode_REJECT_INVALID {
+		t.Errorf("Expected errors without a code to be invalid, got %v", rej.Code)
+	}
+	if reject.Encode(fmt.Errorf("no code")) != nil || reject.Code(nil) != pro.RejectCode_REJECT_NONE {
+		t.Errorf("Expected errors without a code to have no reject message")
+	}
+	decoded := reject.Decode(reject.Encode(rej))
+	if *decoded != *rej {
+		t.Errorf("Expected %+v to survive encoding, got %+v", rej, decoded)
+	}
+}
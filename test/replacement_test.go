@@ -0,0 +1,147 @@
+package test
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/mempool"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/validation"
+	"Coin/pkg/wallet"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplaceByFee(t *testing.T) {
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: fakeCoins{"a:0": 100}, Now: time.Now()}
+	mp := mempool.New(0, 0, 1)
+	parent := spendTx([]string{"a"}, 99)
+	child := spendTx([]string{parent.Hash()}, 89)
+	for _, tx := range []*block.Transaction{parent, child} {
+		if _, err := mp.Add(c, tx); err != nil {
+			t.Fatalf("Expected transaction to be added: %v", err)
+		}
+	}
+
+	spendsReplaced := spendTx([]string{"a"}, 1)
+	spendsReplaced.Inputs = append(spendsReplaced.Inputs, &block.TransactionInput{ReferenceTransactionHash: parent.Hash()})
+	signTx(spendsReplaced)
+	tests := []struct {
+		name            string
+		tx              *block.Transaction
+		maxReplacements int
+		code            pro.RejectCode
+	}{
+		{"lower fee rate", spendTx([]string{"a"}, 98, 1), 100, pro.RejectCode_REJECT_INSUFFICIENT_FEE},
+		{"less than the family pays", spendTx([]string{"a"}, 90), 100, pro.RejectCode_REJECT_INSUFFICIENT_FEE},
+		{"too many evictions", spendTx([]string{"a"}, 50), 1, pro.RejectCode_REJECT_DOUBLE_SPEND},
+		{"spends what it replaces", spendsReplaced, 100, pro.RejectCode_REJECT_INVALID},
+	}
+	for _, test := range tests {
+		mp.MaxReplacements = test.maxReplacements
+		replaced, err := mp.Add(c, test.tx)
+		var verr *validation.Error
+		if !errors.As(err, &verr) || verr.Rule != "replace-by-fee" || !validation.IsPolicy(err) || reject.Code(err) != test.code {
+			t.Errorf("%v: expected the replacement to be rejected with %v, got %v", test.name, test.code, err)
+		}
+		if len(replaced) != 0 || !mp.Has(parent.Hash()) || !mp.Has(child.Hash()) {
+			t.Errorf("%v: expected a rejected replacement to leave the mempool alone", test.name)
+		}
+	}
+
+	mp.MaxReplacements = 2
+	replacement := spendTx([]string{"a"}, 50)
+	replaced, err := mp.Add(c, replacement)
+	if err != nil {
+		t.Fatalf("Expected a replacement that pays more to be accepted: %v", err)
+	}
+	if len(replaced) != 2 || replaced[0].Hash() != parent.Hash() || replaced[1].Hash() != child.Hash() {
+		t.Errorf("Expected the replacement to evict the parent and then its child, got %v transactions", len(replaced))
+	}
+	if mp.Has(parent.Hash()) || mp.Has(child.Hash()) || !mp.Has(replacement.Hash()) || mp.Stats().Replaced != 2 {
+		t.Errorf("Expected the mempool to hold only the replacement: %+v", mp.Stats())
+	}
+}
+
+func TestReplacementMustFitTheMempool(t *testing.T) {
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: fakeCoins{"a:0": 100, "b:0": 100}, Now: time.Now()}
+	parent := spendTx([]string{"a"}, 99)
+	rich := spendTx([]string{"b"}, 50)
+	mp := mempool.New(parent.Size()+rich.Size(), 0, 100)
+	for _, tx := range []*block.Transaction{parent, rich} {
+		if _, err := mp.Add(c, tx); err != nil {
+			t.Fatalf("Expected transaction to be added: %v", err)
+		}
+	}
+	// the replacement pays more than the parent, but is bigger and
+	// pays less per byte than anything else, so the full mempool
+	// would evict it right away
+	replacement := spendTx([]string{"a"}, 90, 1, 1, 1)
+	replaced, err := mp.Add(c, replacement)
+	if reject.Code(err) != pro.RejectCode_REJECT_INSUFFICIENT_FEE || len(replaced) != 0 {
+		t.Errorf("Expected a replacement that does not fit to be rejected, got %v and %v replaced", err, len(replaced))
+	}
+	if !mp.Has(parent.Hash()) || !mp.Has(rich.Hash()) || mp.Has(replacement.Hash()) {
+		t.Errorf("Expected a rejected replacement to leave the mempool alone")
+	}
+	if stats := mp.Stats(); stats.Replaced != 0 || stats.Evicted != 0 || stats.Size != parent.Size()+rich.Size() {
+		t.Errorf("Expected the mempool to be as it was: %+v", stats)
+	}
+}
+
+func TestWalletBumpsFee(t *testing.T) {
+	node := NewGenesisNode()
+	defer CleanUp([]*blockchain.BlockChain{node.BlockChain})
+	w := node.Wallet
+	blocks := MineChain(node.BlockChain, 1)
+	coinbase := &block.Transaction{
+		Outputs:  []*block.TransactionOutput{{Amount: 100, LockingScript: node.Id.GetPublicKeyString()}},
+		LockTime: 1,
+	}
+	funding := MineBlock(blocks[0].Hash(), []*block.Transaction{coinbase}, blocks[0].Header.Timestamp+1)
+	node.BlockChain.HandleBlock(funding)
+	w.CoinCollection[coinbase.Outputs[0]] = &wallet.CoinInfo{ReferenceTransactionHash: coinbase.Hash(), TransactionOutput: coinbase.Outputs[0]}
+	w.Balance = 100
+
+	payment := w.RequestTransaction(10, 1, []byte("recipient"))
+	if payment == nil {
+		t.Fatalf("Expected the wallet to make a payment")
+	}
+	if err := node.BroadcastTransaction(payment); err != nil {
+		t.Fatalf("Expected the payment to be broadcast: %v", err)
+	}
+	CheckTransactionInMempool(t, node, payment)
+	invalid := spendTx([]string{"unknown"}, 1)
+	if node.BroadcastTransaction(invalid) == nil || node.SeenTransactions.Contains(invalid.Hash()) {
+		t.Errorf("Expected a transaction the mempool rejects not to be broadcast")
+	}
+	if node.BumpFee(payment.Hash(), 1) != nil || node.BumpFee("unknown", 5) != nil {
+		t.Errorf("Expected only pending payments to be bumped to a higher fee")
+	}
+
+	replacement := node.BumpFee(payment.Hash(), 5)
+	if replacement == nil {
+		t.Fatalf("Expected the wallet to bump the fee")
+	}
+	if replacement.Outputs[0].Amount != 10 || replacement.Outputs[1].Amount != 85 {
+		t.Errorf("Expected the higher fee to come out of the change")
+	}
+	CheckTransactionInMempool(t, node, replacement)
+	if node.Mempool.Has(payment.Hash()) || w.Pending[payment.Hash()] != nil || w.Pending[replacement.Hash()] == nil {
+		t.Errorf("Expected the replacement to take the place of the payment")
+	}
+	res, err := node.GetObjects(context.Background(), &pro.GetObjectsRequest{Items: []*pro.InventoryItem{
+		{Type: pro.InventoryType_INVENTORY_TRANSACTION, Hash: payment.Hash()},
+		{Type: pro.InventoryType_INVENTORY_TRANSACTION, Hash: replacement.Hash()},
+	}})
+	if err != nil || len(res.Transactions) != 1 || block.DecodeTransaction(res.Transactions[0]).Hash() != replacement.Hash() {
+		t.Errorf("Expected peers to be offered only the replacement")
+	}
+
+	w.HandleBlock([]*block.Transaction{replacement})
+	if len(w.Pending) != 0 {
+		t.Errorf("Expected a confirmed replacement to no longer be pending")
+	}
+}
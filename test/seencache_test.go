@@ -0,0 +1,70 @@
+package test
+
+import (
+	"Coin/pkg/cache"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecentIsBoundedBySize(t *testing.T) {
+	r := cache.NewRecent(3, 0)
+	for _, key := range []string{"a", "b", "c"} {
+		if !r.Add(key) {
+			t.Errorf("Expected %v to be new", key)
+		}
+	}
+	if r.Add("a") {
+		t.Errorf("Expected a to be remembered")
+	}
+	// a was added again, so b is the oldest
+	r.Add("d")
+	if r.Contains("b") || !r.Contains("a") || r.Len() != 3 {
+		t.Errorf("Expected the oldest key to be forgotten")
+	}
+	s := r.Stats()
+	if s.Entries != 3 || s.Evicted != 1 || s.MemoryBytes <= 0 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+	// lookups: a, b, c, a, d miss or hit, then b (miss) and a (hit)
+	if s.Hits != 2 || s.Misses != 5 || s.HitRate != 2.0/7 {
+		t.Errorf("Unexpected hit rate in %+v", s)
+	}
+}
+
+func TestRecentForgetsOldKeys(t *testing.T) {
+	r := cache.NewRecent(10, 50*time.Millisecond)
+	r.Add("a")
+	time.Sleep(100 * time.Millisecond)
+	if r.Contains("a") {
+		t.Errorf("Expected a to expire")
+	}
+	if s := r.Stats(); s.Expired != 1 || s.Entries != 0 || s.MemoryBytes != 0 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+func TestRecentIsSafeForConcurrentUse(t *testing.T) {
+	r := cache.NewRecent(100, time.Minute)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	added := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if r.Add(fmt.Sprint(j)) {
+					mutex.Lock()
+					added++
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if added != 50 || r.Len() != 50 {
+		t.Errorf("Expected every key to be added once, added %v", added)
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"Coin/pkg/address"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/pro"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionCarriesConcurrentRequests(t *testing.T) {
+	cluster := NewCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	a := cluster[0]
+	StartCluster(cluster)
+	defer a.Kill()
+	addr := address.New(a.Address, 0)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	failed := 0
+	// more requests than fit in flight at once
+	for i := 0; i < 3*address.MaxInFlight; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := addr.GetAddressesRPC(&pro.Empty{}); err != nil {
+				mutex.Lock()
+				failed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if failed > 0 {
+		t.Errorf("Expected every request to succeed, %v failed", failed)
+	}
+}
+
+func TestSessionReconnects(t *testing.T) {
+	cluster := NewCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	a := cluster[0]
+	StartCluster(cluster)
+	defer a.Kill()
+	addr := address.New(a.Address, 0)
+	if _, err := addr.GetAddressesRPC(&pro.Empty{}); err != nil {
+		t.Fatalf("Expected the request to succeed: %v", err)
+	}
+	a.PauseNetwork()
+	if _, err := addr.GetAddressesRPC(&pro.Empty{}); err == nil {
+		t.Errorf("Expected the request to a paused node to fail")
+	}
+	a.ResumeNetwork()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := addr.GetAddressesRPC(&pro.Empty{}); err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the session to reconnect")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestClosedTransportsFailRequests(t *testing.T) {
+	cluster := NewCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	a := cluster[0]
+	StartCluster(cluster)
+	defer a.Kill()
+	addr := address.New(a.Address, 0)
+	addr.Transport = address.NewTransport(nil)
+	if _, err := addr.GetAddressesRPC(&pro.Empty{}); err != nil {
+		t.Fatalf("Expected the request to succeed: %v", err)
+	}
+	// a dropped session is replaced by a new one
+	addr.Transport.Drop(a.Address)
+	if _, err := addr.GetAddressesRPC(&pro.Empty{}); err != nil {
+		t.Errorf("Expected the request after a drop to succeed: %v", err)
+	}
+	addr.Transport.Close()
+	if _, err := addr.GetAddressesRPC(&pro.Empty{}); err == nil {
+		t.Errorf("Expected the request over a closed transport to fail")
+	}
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/address"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"testing"
+	"time"
+)
+
+// waitForLength waits until the node's chain has the given length.
+func waitForLength(t *testing.T, n *pkg.Node, length uint32) {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for _, have := n.ChainTip(); have != length; _, have = n.ChainTip() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for chain of length %v, have %v", length, have)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestNodeCatchesUpWithoutBootstrap(t *testing.T) {
+	cluster := NewCluster(2)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain})
+	full, syncing := cluster[0], cluster[1]
+	// only peer announcements should trigger a sync
+	syncing.Config.SyncInterval = time.Hour
+
+	// more than a single GetBlocks response can hold
+	MineChain(full.BlockChain, 600)
+	StartCluster(cluster)
+	defer full.Kill()
+	defer syncing.Kill()
+	syncing.ConnectToPeer(full.Address)
+	waitForLength(t, syncing, full.BlockChain.Length)
+	CheckMainChains(t, cluster)
+	if syncing.BestPeerHeight() != full.BlockChain.Length {
+		t.Errorf("Expected best peer height %v, got %v", full.BlockChain.Length, syncing.BestPeerHeight())
+	}
+
+	// the syncing node falls behind, and only hears about the newest block
+	MineChain(full.BlockChain, 5)
+	coinbase := &block.Transaction{
+		Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}},
+		LockTime: full.BlockChain.Length,
+	}
+	b := MineBlock(full.BlockChain.LastHash, []*block.Transaction{coinbase}, full.BlockChain.LastBlock.Header.Timestamp+1)
+	full.BlockChain.HandleBlock(b)
+	if _, err := address.New(syncing.Address, 0).ForwardBlockRPC(block.EncodeBlock(b)); err == nil {
+		t.Errorf("Expected block with unknown parent to be rejected")
+	}
+	waitForLength(t, syncing, full.BlockChain.Length)
+	CheckMainChains(t, cluster)
+}
@@ -17,17 +17,40 @@ import (
 	"time"
 )
 
+// testPOWLimit is the proof of work limit of test chains. It is
+// much easier than the default, so that tests can mine long chains.
+var testPOWLimit = string(utils.CalcPOWD(1))
+
+// owner is the key that the coins tests make are locked to, so
+// that tests can spend them (see signTx).
+var owner, _ = id.CreateSimpleID()
+
+// signTx signs every input of tx with the owner's key, once tx
+// is complete, and returns it.
+func signTx(tx *block.Transaction) *block.Transaction {
+	txo := &block.TransactionOutput{LockingScript: owner.GetPublicKeyString()}
+	for _, txi := range tx.Inputs {
+		txi.UnlockingScript, _ = txo.MakeSignature(owner, tx)
+	}
+	return tx
+}
+
 func setNodeConfig(conf *pkg.Config, i int) *pkg.Config {
+	conf.ChainConfig.POWLimit = testPOWLimit
 	conf.ChainConfig.BlockInfoDBPath = "blockinfodata" + strconv.Itoa(i)
 	conf.ChainConfig.CoinDBPath = "coindata" + strconv.Itoa(i)
 	conf.ChainConfig.ChainWriterDBPath = "data" + strconv.Itoa(i)
+	conf.BanListPath = "banlist" + strconv.Itoa(i)
+	conf.AddressDBPath = "addressdata" + strconv.Itoa(i)
+	conf.PeerDBPath = "peerdata" + strconv.Itoa(i)
+	conf.MempoolPath = "mempool" + strconv.Itoa(i)
 	return conf
 }
 
 // CleanUp is used to clean up testing side effects, where num is
 // the number of blockchains (which create directories)
 func CleanUp(chains []*blockchain.BlockChain) {
-	paths := []string{"coindata", "blockinfodata", "data"}
+	paths := []string{"coindata", "blockinfodata", "data", "banlist", "addressdata", "peerdata", "mempool"}
 	for i, chain := range chains {
 		// manually close the levelDBs
 		chain.BlockInfoDB.Close()
@@ -37,7 +60,7 @@ func CleanUp(chains []*blockchain.BlockChain) {
 			path += strconv.Itoa(i)
 			if _, err := os.Stat(path); !os.IsNotExist(err) {
 				if err2 := os.RemoveAll(path); err2 != nil {
-					fmt.Errorf("coudld not remove %v", path)
+					fmt.Printf("could not remove %v\n", path)
 				}
 			}
 		}
@@ -108,7 +131,7 @@ func CheckMainChains(t *testing.T, nodes []*pkg.Node) {
 func CheckTransactionSeen(t *testing.T, nodes []*pkg.Node, tx *block.Transaction) {
 	t.Helper()
 	for _, n := range nodes {
-		if _, ok := n.SeenTransactions[tx.Hash()]; !ok {
+		if !n.SeenTransactions.Contains(tx.Hash()) {
 			t.Errorf("Error: node {%v} should have seen transaction {%v}", utils.FmtAddr(n.Address), tx.Hash())
 		}
 	}
@@ -199,10 +222,10 @@ func AssertSize(t *testing.T, actualSize int, expectedSize int) {
 	}
 }
 
-func CheckTransactionInTXPool(t *testing.T, n *pkg.Node, tx *block.Transaction) {
+func CheckTransactionInMempool(t *testing.T, n *pkg.Node, tx *block.Transaction) {
 	t.Helper()
-	if !n.Miner.TxPool.TxQ.Has(tx) {
-		t.Errorf("Miner {%v} did not have transaction %v in its TXPool", utils.FmtAddr(n.Miner.Address), tx.Hash())
+	if !n.Mempool.Has(tx.Hash()) {
+		t.Errorf("Node {%v} did not have transaction %v in its mempool", utils.FmtAddr(n.Address), tx.Hash())
 	}
 }
 
@@ -247,3 +270,35 @@ func GenerateTransactions(transactions []*block.Transaction) []*block.Transactio
 	}
 	return txs
 }
+
+// MineBlock creates a block on top of prevHash with the given
+// transactions and timestamp, and finds a nonce for it that
+// meets the test proof of work limit.
+func MineBlock(prevHash string, txs []*block.Transaction, timestamp uint32) *block.Block {
+	b := block.New(prevHash, txs, testPOWLimit, timestamp)
+	for !b.Header.MeetsTarget() {
+		b.Header.Nonce++
+	}
+	return b
+}
+
+// MineChain mines n blocks on top of the chain's last block,
+// each holding a single coinbase transaction, and adds them
+// to the chain. It returns the mined blocks.
+func MineChain(chain *blockchain.BlockChain, n int) []*block.Block {
+	var blocks []*block.Block
+	ts := chain.LastBlock.Header.Timestamp + 1
+	if now := uint32(time.Now().Unix()); now > ts {
+		ts = now
+	}
+	for i := 0; i < n; i++ {
+		coinbase := &block.Transaction{
+			Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: owner.GetPublicKeyString()}},
+			LockTime: uint32(chain.Length),
+		}
+		b := MineBlock(chain.LastHash, []*block.Transaction{coinbase}, ts+uint32(i))
+		chain.HandleBlock(b)
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/clock"
+	"testing"
+	"time"
+)
+
+func TestAdjustedClockUsesMedianOffset(t *testing.T) {
+	base := clock.NewManualClock(time.Unix(1_000_000, 0))
+	c := clock.NewAdjustedClock(base, 3, 10, time.Hour)
+	c.AddSample("a", base.Now().Add(10*time.Second))
+	c.AddSample("b", base.Now().Add(20*time.Second))
+	if c.Offset() != 0 {
+		t.Errorf("Expected no offset before enough samples, got %v", c.Offset())
+	}
+	c.AddSample("c", base.Now().Add(30*time.Second))
+	// offsets are {0, 10, 20, 30}, so the median is 20 seconds
+	if c.Offset() != 20*time.Second {
+		t.Errorf("Expected offset of 20s, got %v", c.Offset())
+	}
+	if !c.Now().Equal(base.Now().Add(20 * time.Second)) {
+		t.Errorf("Expected adjusted time to include the offset")
+	}
+	// a peer re-reporting replaces its old sample
+	c.AddSample("c", base.Now().Add(-30*time.Second))
+	if c.Offset() != 10*time.Second {
+		t.Errorf("Expected offset of 10s, got %v", c.Offset())
+	}
+}
+
+func TestAdjustedClockIgnoresLargeOffsets(t *testing.T) {
+	base := clock.NewManualClock(time.Unix(1_000_000, 0))
+	c := clock.NewAdjustedClock(base, 1, 10, time.Minute)
+	c.AddSample("a", base.Now().Add(time.Hour))
+	c.AddSample("b", base.Now().Add(time.Hour))
+	if c.Offset() != 0 {
+		t.Errorf("Expected an offset above the limit to be ignored, got %v", c.Offset())
+	}
+}
+
+func TestCheckBlockTimestamp(t *testing.T) {
+	now := time.Unix(1_600_000_000, 0)
+	conf := setNodeConfig(GenesisConfig(GetFreePort()), 0)
+	conf.Clock = clock.NewManualClock(now)
+	node := pkg.New(conf)
+	defer CleanUp([]*blockchain.BlockChain{node.BlockChain})
+
+	genHash := node.BlockChain.LastHash
+	txs := GenerateTransactions(nil)[:1]
+	stale := block.New(genHash, txs, "", 0)
+	if node.CheckBlockTimestamp(stale) {
+		t.Errorf("Expected block at the median time past to be rejected")
+	}
+	current := block.New(genHash, txs, "", uint32(now.Unix()))
+	if !node.CheckBlockTimestamp(current) {
+		t.Errorf("Expected block stamped with the current time to be accepted")
+	}
+	future := block.New(genHash, txs, "", uint32(now.Add(3*time.Hour).Unix()))
+	if node.CheckBlockTimestamp(future) {
+		t.Errorf("Expected block three hours in the future to be rejected")
+	}
+	conf.Clock.(*clock.ManualClock).Advance(2 * time.Hour)
+	if !node.CheckBlockTimestamp(future) {
+		t.Errorf("Expected block to be accepted once the clock caught up")
+	}
+}
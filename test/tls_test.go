@@ -0,0 +1,119 @@
+package test
+
+import (
+	"Coin/pkg"
+	"Coin/pkg/address"
+	"Coin/pkg/block"
+	"Coin/pkg/blockchain"
+	"Coin/pkg/pro"
+	"bytes"
+	"testing"
+	"time"
+)
+
+// newTLSCluster returns n nodes that only talk over TLS,
+// the first of which is the genesis node.
+func newTLSCluster(n int) []*pkg.Node {
+	var cluster []*pkg.Node
+	for i := 0; i < n; i++ {
+		conf := pkg.DefaultConfig(GetFreePort())
+		if i == 0 {
+			conf = GenesisConfig(conf.Port)
+		}
+		conf = setNodeConfig(conf, i)
+		conf.TLS = true
+		cluster = append(cluster, pkg.New(conf))
+	}
+	return cluster
+}
+
+func TestTLSNodesAuthenticatePeers(t *testing.T) {
+	cluster := newTLSCluster(3)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	a, b, c := cluster[0], cluster[1], cluster[2]
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	// a line: a <-> b <-> c
+	a.ConnectToPeer(b.Address)
+	b.ConnectToPeer(c.Address)
+	for _, pair := range [][2]*pkg.Node{{a, b}, {b, a}, {b, c}, {c, b}} {
+		p := pair[0].PeerDb.Get(pair[1].Address)
+		if p == nil {
+			t.Fatalf("Expected %v to be a peer of %v", pair[1].Address, pair[0].Address)
+		}
+		if !bytes.Equal(p.Identity, pair[1].Id.GetPublicKeyBytes()) {
+			t.Errorf("Expected the peer %v to have proven its identity", pair[1].Address)
+		}
+	}
+
+	coinbase := &block.Transaction{
+		Outputs:  []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}},
+		LockTime: a.BlockChain.Length,
+	}
+	a.HandleMinerBlock(MineBlock(a.BlockChain.LastHash, []*block.Transaction{coinbase}, uint32(time.Now().Unix())))
+	waitForLength(t, c, 2)
+	CheckMainChains(t, cluster)
+}
+
+func TestTLSNodesRejectInsecureRequests(t *testing.T) {
+	cluster := newTLSCluster(1)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain})
+	a := cluster[0]
+	StartCluster(cluster)
+	defer a.Kill()
+	if _, err := address.New(a.Address, 0).GetAddressesRPC(&pro.Empty{}); err == nil {
+		t.Errorf("Expected a request without TLS to fail")
+	}
+	addr := address.New(a.Address, 0)
+	addr.Transport = a.Transport
+	if _, err := addr.GetAddressesRPC(&pro.Empty{}); err != nil {
+		t.Errorf("Expected a request over TLS to succeed: %v", err)
+	}
+}
+
+func TestTLSNodesRejectClaimedIdentities(t *testing.T) {
+	cluster := newTLSCluster(3)
+	defer CleanUp([]*blockchain.BlockChain{cluster[0].BlockChain, cluster[1].BlockChain, cluster[2].BlockChain})
+	a, b, c := cluster[0], cluster[1], cluster[2]
+	StartCluster(cluster)
+	for _, n := range cluster {
+		defer n.Kill()
+	}
+	b.ConnectToPeer(a.Address)
+	addr := address.New(a.Address, 0)
+	addr.Transport = c.Transport
+	// c claims b's identity, which it cannot prove
+	if _, err := addr.VersionRPC(&pro.VersionRequest{
+		Version:  uint32(c.Config.Version),
+		AddrMe:   c.Address,
+		Identity: b.Id.GetPublicKeyBytes(),
+	}); err == nil {
+		t.Errorf("Expected a version claiming another identity to be rejected")
+	}
+	// c claims b's address with its own identity
+	if _, err := addr.VersionRPC(&pro.VersionRequest{
+		Version:  uint32(c.Config.Version),
+		AddrMe:   b.Address,
+		Identity: c.Id.GetPublicKeyBytes(),
+	}); err == nil {
+		t.Errorf("Expected a version claiming a peer's address to be rejected")
+	}
+	// c sends an announcement in b's name
+	if _, err := addr.AnnounceRPC(&pro.Inventory{AddrMe: b.Address}); err == nil {
+		t.Errorf("Expected an announcement in a peer's name to be rejected")
+	}
+	if _, err := addr.GetBlocksRPC(&pro.GetBlocksRequest{TopBlockHash: a.BlockChain.LastHash, AddrMe: b.Address}); err == nil {
+		t.Errorf("Expected a block request in a peer's name to be rejected")
+	}
+	if _, err := addr.SendAddressesRPC(&pro.Addresses{AddrMe: b.Address}); err == nil {
+		t.Errorf("Expected addresses sent in a peer's name to be rejected")
+	}
+	if p := a.PeerDb.Get(b.Address); p == nil || !bytes.Equal(p.Identity, b.Id.GetPublicKeyBytes()) {
+		t.Errorf("Expected b to stay a peer of a")
+	}
+	if a.PeerDb.Get(c.Address) != nil {
+		t.Errorf("Expected c not to become a peer of a")
+	}
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"Coin/pkg/block"
+	"Coin/pkg/id"
+	"Coin/pkg/pro"
+	"Coin/pkg/reject"
+	"Coin/pkg/validation"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeCoins are coins worth an amount, by "hash:index", that
+// belong to the owner.
+type fakeCoins map[string]uint32
+
+func (fc fakeCoins) Coin(txi *block.TransactionInput) (*block.TransactionOutput, error) {
+	amt, ok := fc[fmt.Sprintf("%v:%v", txi.ReferenceTransactionHash, txi.OutputIndex)]
+	if !ok {
+		return nil, reject.New(pro.RejectCode_REJECT_MISSING_INPUTS, "", "no such coin")
+	}
+	return &block.TransactionOutput{Amount: amt, LockingScript: owner.GetPublicKeyString()}, nil
+}
+
+// spendTx returns a transaction signed by the owner that spends
+// the first output of each input and pays the outputs to the
+// owner.
+func spendTx(inputs []string, outputs ...uint32) *block.Transaction {
+	tx := &block.Transaction{}
+	for _, in := range inputs {
+		tx.Inputs = append(tx.Inputs, &block.TransactionInput{ReferenceTransactionHash: in})
+	}
+	for _, amt := range outputs {
+		tx.Outputs = append(tx.Outputs, &block.TransactionOutput{Amount: amt, LockingScript: owner.GetPublicKeyString()})
+	}
+	return signTx(tx)
+}
+
+func TestTransactionRules(t *testing.T) {
+	coins := fakeCoins{"a:0": 100, "b:0": math.MaxUint32}
+	c := &validation.Context{Params: validation.DefaultParams(), Coins: coins}
+	// sizes only count fields, so a large transaction has many outputs
+	big := spendTx([]string{"b"}, 1)
+	for big.Size() <= validation.DefaultParams().MaxStandardTransactionSize {
+		big.Outputs = append(big.Outputs, big.Outputs...)
+	}
+	signTx(big)
+	unsigned := spendTx([]string{"a"}, 90)
+	unsigned.Inputs[0].UnlockingScript = ""
+	stranger, _ := id.CreateSimpleID()
+	stolen := spendTx([]string{"a"}, 90)
+	stolen.Inputs[0].UnlockingScript, _ = (&block.TransactionOutput{}).MakeSignature(stranger, stolen)
+	// a signature only lets the transaction it signs spend the coin
+	reused := spendTx([]string{"a"}, 50)
+	reused.Inputs[0].UnlockingScript = spendTx([]string{"a"}, 90).Inputs[0].UnlockingScript
+	tests := []struct {
+		name   string
+		tx     *block.Transaction
+		rule   string
+		code   pro.RejectCode
+		policy bool
+	}{
+		{"valid", spendTx([]string{"a"}, 60, 30), "", pro.RejectCode_REJECT_NONE, false},
+		{"no inputs", spendTx(nil, 10), "inputs-and-outputs", pro.RejectCode_REJECT_MALFORMED, false},
+		{"no outputs", spendTx([]string{"a"}), "inputs-and-outputs", pro.RejectCode_REJECT_MALFORMED, false},
+		{"worthless output", spendTx([]string{"a"}, 10, 0), "positive-outputs", pro.RejectCode_REJECT_MALFORMED, false},
+		{"overflow", spendTx([]string{"b"}, math.MaxUint32, 1), "output-overflow", pro.RejectCode_REJECT_MALFORMED, false},
+		{"duplicate inputs", spendTx([]string{"a", "a"}, 10), "duplicate-inputs", pro.RejectCode_REJECT_DOUBLE_SPEND, false},
+		{"missing inputs", spendTx([]string{"c"}, 10), "inputs-exist", pro.RejectCode_REJECT_MISSING_INPUTS, false},
+		{"overspends", spendTx([]string{"a"}, 101), "inputs-cover-outputs", pro.RejectCode_REJECT_INSUFFICIENT_FEE, false},
+		{"not signed", unsigned, "signatures", pro.RejectCode_REJECT_INVALID_SIGNATURE, false},
+		{"signed by someone else", stolen, "signatures", pro.RejectCode_REJECT_INVALID_SIGNATURE, false},
+		{"signature of another transaction", reused, "signatures", pro.RejectCode_REJECT_INVALID_SIGNATURE, false},
+		{"not standard", big, "standard-size", pro.RejectCode_REJECT_OVERSIZED, true},
+		{"no fee", spendTx([]string{"a"}, 100), "min-relay-fee", pro.RejectCode_REJECT_INSUFFICIENT_FEE, true},
+	}
+	for _, test := range tests {
+		err := validation.CheckTransaction(c, test.tx, validation.TransactionRules)
+		if test.rule == "" {
+			if err != nil {
+				t.Errorf("%v: expected no error, got %v", test.name, err)
+			}
+			continue
+		}
+		var verr *validation.Error
+		if !errors.As(err, &verr) {
+			t.Errorf("%v: expected rule %v to be broken, got %v", test.name, test.rule, err)
+			continue
+		}
+		if verr.Rule != test.rule || reject.Code(err) != test.code || validation.IsPolicy(err) != test.policy {
+			t.Errorf("%v: expected rule %v (%v, policy %v), got %v (%v, policy %v)", test.name,
+				test.rule, test.code, test.policy, verr.Rule, reject.Code(err), validation.IsPolicy(err))
+		}
+		if verr.Reject.Hash != test.tx.Hash() {
+			t.Errorf("%v: expected the rejection to name the transaction", test.name)
+		}
+	}
+
+	// without coins, only the rules that need nothing but the
+	// transaction are checked
+	if err := validation.CheckTransaction(&validation.Context{}, spendTx([]string{"c"}, 10), validation.TransactionRules); err != nil {
+		t.Errorf("Expected a context without coins to skip the coin rules, got %v", err)
+	}
+}
+
+func TestBlockRules(t *testing.T) {
+	coins := fakeCoins{"a:0": 100}
+	now := time.Now()
+	ts := uint32(now.Unix())
+	c := &validation.Context{
+		Params:         validation.DefaultParams(),
+		Coins:          coins,
+		Now:            now,
+		MedianTimePast: ts - 10,
+	}
+	coinbase := func() *block.Transaction {
+		return &block.Transaction{Outputs: []*block.TransactionOutput{{Amount: 50, LockingScript: "miner"}}}
+	}
+	spend := spendTx([]string{"a"}, 90)
+	child := spendTx([]string{spend.Hash()}, 80)
+	mine := func(timestamp uint32, txs ...*block.Transaction) *block.Block {
+		return MineBlock("prev", txs, timestamp)
+	}
+
+	badRoot := mine(ts, coinbase(), spend)
+	badRoot.Header.MerkleRoot = "wrong"
+	for !badRoot.Header.MeetsTarget() {
+		badRoot.Header.Nonce++
+	}
+	badPOW := block.New("prev", []*block.Transaction{coinbase()}, testPOWLimit, ts)
+	for badPOW.Header.MeetsTarget() {
+		badPOW.Header.Nonce++
+	}
+	forged := spendTx([]string{"a"}, 90)
+	forged.Inputs[0].UnlockingScript = ""
+	large := spendTx([]string{"a"}, 1)
+	for large.Size() <= 500 {
+		large.Outputs = append(large.Outputs, large.Outputs...)
+	}
+
+	tests := []struct {
+		name string
+		b    *block.Block
+		rule string
+		code pro.RejectCode
+	}{
+		{"valid", mine(ts, coinbase(), spend, child), "", pro.RejectCode_REJECT_NONE},
+		{"empty", &block.Block{Header: &block.Header{}}, "has-transactions", pro.RejectCode_REJECT_MALFORMED},
+		{"no coinbase", mine(ts, spend), "coinbase", pro.RejectCode_REJECT_MALFORMED},
+		{"two coinbases", mine(ts, coinbase(), coinbase()), "coinbase", pro.RejectCode_REJECT_MALFORMED},
+		{"merkle root", badRoot, "merkle-root", pro.RejectCode_REJECT_INVALID},
+		{"proof of work", badPOW, "proof-of-work", pro.RejectCode_REJECT_BAD_POW},
+		{"median time past", mine(ts-10, coinbase()), "timestamp", pro.RejectCode_REJECT_BAD_TIMESTAMP},
+		{"future", mine(uint32(now.Add(3*time.Hour).Unix()), coinbase()), "timestamp", pro.RejectCode_REJECT_BAD_TIMESTAMP},
+		{"invalid transaction", mine(ts, coinbase(), spendTx([]string{"a"}, 101)), "transactions", pro.RejectCode_REJECT_INSUFFICIENT_FEE},
+		{"forged signature", mine(ts, coinbase(), forged), "transactions", pro.RejectCode_REJECT_INVALID_SIGNATURE},
+		{"double spend", mine(ts, coinbase(), spend, spendTx([]string{"a"}, 10)), "transactions", pro.RejectCode_REJECT_DOUBLE_SPEND},
+		{"child first", mine(ts, coinbase(), child, spend), "transactions", pro.RejectCode_REJECT_MISSING_INPUTS},
+	}
+	for _, test := range tests {
+		err := validation.CheckBlock(c, test.b, validation.BlockRules)
+		if test.rule == "" {
+			if err != nil {
+				t.Errorf("%v: expected no error, got %v", test.name, err)
+			}
+			continue
+		}
+		var verr *validation.Error
+		if !errors.As(err, &verr) {
+			t.Errorf("%v: expected rule %v to be broken, got %v", test.name, test.rule, err)
+			continue
+		}
+		if verr.Rule != test.rule || reject.Code(err) != test.code || validation.IsPolicy(err) {
+			t.Errorf("%v: expected consensus rule %v (%v), got %v (%v)", test.name,
+				test.rule, test.code, verr.Rule, reject.Code(err))
+		}
+	}
+
+	c.Params.MaxBlockSize = 500
+	if err := validation.CheckBlock(c, mine(ts, coinbase(), large), validation.BlockRules); reject.Code(err) != pro.RejectCode_REJECT_OVERSIZED {
+		t.Errorf("Expected a block over the maximum size to be oversized, got %v", err)
+	}
+}